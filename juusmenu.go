@@ -44,8 +44,10 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 )
 
 func init() {
@@ -53,17 +55,6 @@ func init() {
 		menuScanner = bufio.NewScanner(os.Stdin)
 	}
 
-	//No method to change aligner alignment-side during run-time, odd.
-	//So have to make two...
-	if alignerLeft == nil {
-		alignerLeft = tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	}
-	if alignerRight == nil {
-		alignerRight = tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
-	}
-
-	alignerLocal = alignerLeft
-
 	MenuOptions = &menuOptions{
 		funcBracketTop:        funcBracketTopStr,
 		funcBracketBottom:     funcBracketBottomStr,
@@ -74,6 +65,19 @@ func init() {
 		pauseOnOutput:         true,
 		runTimeErrMsgsDisplay: true,
 		runTimeErrMsgsPause:   true,
+		suggestKeys:           true,
+		suggestRun:            false,
+		confirmBypass:         false,
+		historyFile:           "",
+		historyLimit:          defhistoryLimit,
+		lineEditor:            deflineEditor,
+		fullScreen:            deffullScreen,
+		renderer:              nil,
+		theme:                 ThemePlain,
+		colorMode:             ColorAuto,
+		longHints:             LongHintWrap,
+		lineWidth:             0,
+		outputMode:            OutputDirect,
 	}
 }
 
@@ -89,16 +93,22 @@ var (
 	MenuOptions *menuOptions
 	//private
 	additionalString         = "\n^^^ Important information above, please read..."
-	alignerLeft              *tabwriter.Writer
-	alignerLocal             *tabwriter.Writer
-	alignerRight             *tabwriter.Writer
+	alignRight               bool
 	allMenus                 menuList
+	alphaKeyAlphabet         = "abcdefghijklmnopqrstuvwxyz"
 	defBreakh                = "Quit this Menu"
 	defBreakv                = "QQ.QQ"
 	defidFuncRunner          = true
 	defpauseOnOutput         = true
 	defrunTimeErrMsgsDisplay = true
 	defrunTimeErrMsgsPause   = true
+	defsuggestKeys           = true
+	defsuggestRun            = false
+	defconfirmBypass         = false
+	defhistoryLimit          = 0
+	deflineEditor            = false
+	deffullScreen            = false
+	defConfirmQuestion       = "Are you sure?"
 	emptyHint                = "Menu hint not specified"
 	emptyString              = ""
 	funcBracketBottomStr     = "..............*"
@@ -117,7 +127,10 @@ const (
 	//breakIndicator: menuEntries "value" that self-manages by carrying the loop "break" key
 	breakIndicator = "^,^BrEaK^*^" //unlikely to be typed as a map Key by anybody
 	killTemplate   = "===============  '%s' immediately exits all Menus  ========="
-	menuFormat     = "%s\t: \t%s"
+	badgeFormat    = "[%s]"
+	disabledFormat = "%s  (unavailable: %s)"
+	sectionFormat  = "--- %s ---"
+	separatorLine  = "- - - - - - - -"
 	trimString     = " \t\r\n"
 )
 
@@ -157,6 +170,19 @@ type menuOptions struct {
 	pauseOnOutput         bool
 	runTimeErrMsgsDisplay bool
 	runTimeErrMsgsPause   bool
+	suggestKeys           bool
+	suggestRun            bool
+	confirmBypass         bool
+	historyFile           string
+	historyLimit          int
+	lineEditor            bool
+	fullScreen            bool
+	renderer              Renderer
+	theme                 Theme
+	colorMode             ColorMode
+	longHints             LongHintMode
+	lineWidth             int
+	outputMode            OutputMode
 }
 
 //Stringer for menuOptions
@@ -170,6 +196,19 @@ func (mo *menuOptions) String() string {
 		fmt.Sprintf(f, "pauseOnOutput", mo.pauseOnOutput, defpauseOnOutput) + "\n" +
 		fmt.Sprintf(f, "runTimeErrMsgsDisplay", mo.runTimeErrMsgsDisplay, defrunTimeErrMsgsDisplay) + "\n" +
 		fmt.Sprintf(f, "runTimeErrMsgsPause", mo.runTimeErrMsgsPause, defrunTimeErrMsgsPause) + "\n" +
+		fmt.Sprintf(f, "suggestKeys", mo.suggestKeys, defsuggestKeys) + "\n" +
+		fmt.Sprintf(f, "suggestRun", mo.suggestRun, defsuggestRun) + "\n" +
+		fmt.Sprintf(f, "confirmBypass", mo.confirmBypass, defconfirmBypass) + "\n" +
+		fmt.Sprintf(f, "historyFile", mo.historyFile, "") + "\n" +
+		fmt.Sprintf(f, "historyLimit", mo.historyLimit, defhistoryLimit) + "\n" +
+		fmt.Sprintf(f, "lineEditor", mo.lineEditor, deflineEditor) + "\n" +
+		fmt.Sprintf(f, "fullScreen", mo.fullScreen, deffullScreen) + "\n" +
+		fmt.Sprintf(f, "renderer", rendererName(mo.renderer), rendererName(nil)) + "\n" +
+		fmt.Sprintf(f, "theme", mo.theme.Name, ThemePlain.Name) + "\n" +
+		fmt.Sprintf(f, "colorMode", mo.colorMode, ColorAuto) + "\n" +
+		fmt.Sprintf(f, "longHints", mo.longHints, LongHintWrap) + "\n" +
+		fmt.Sprintf(f, "lineWidth", mo.lineWidth, 0) + "\n" +
+		fmt.Sprintf(f, "outputMode", mo.outputMode, OutputDirect) + "\n" +
 		fmt.Sprintf(f, "funcBracketTop", mo.funcBracketTop, funcBracketTopStr) + "\n" +
 		fmt.Sprintf(f, "funcBracketBottom", mo.funcBracketBottom, funcBracketBottomStr) + "\n" +
 		fmt.Sprint("\n>> execute fmt.Println(<unit>.MenuOptions.InfoMenuOptions()) for field information") + "\n\n"
@@ -185,6 +224,19 @@ func (mo *menuOptions) InfoMenuOptions() string {
 		pauseOnOutputInfo + "\n\n" +
 		runTimeErrMsgsDisplayInfo + "\n\n" +
 		runTimeErrMsgsPauseInfo + "\n\n" +
+		suggestKeysInfo + "\n\n" +
+		suggestRunInfo + "\n\n" +
+		confirmBypassInfo + "\n\n" +
+		historyFileInfo + "\n\n" +
+		historyLimitInfo + "\n\n" +
+		lineEditorInfo + "\n\n" +
+		fullScreenInfo + "\n\n" +
+		rendererInfo + "\n\n" +
+		themeInfo + "\n\n" +
+		colorModeInfo + "\n\n" +
+		longHintsInfo + "\n\n" +
+		lineWidthInfo + "\n\n" +
+		outputModeInfo + "\n\n" +
 		funcBracketTopInfo + "\n\n" +
 		funcBracketBottomInfo + "\n\n" +
 		fmt.Sprint(">> execute fmt.Println(<unit>.MenuOptions()) for field values") + "\n\n"
@@ -192,12 +244,12 @@ func (mo *menuOptions) InfoMenuOptions() string {
 
 //AlignRight : align the Menu values to the right
 func (mo *menuOptions) AlignRight() {
-	alignerLocal = alignerRight
+	alignRight = true
 }
 
 //AlignLeft : align the Menu values to the left
 func (mo *menuOptions) AlignLeft() {
-	alignerLocal = alignerLeft
+	alignRight = false
 }
 
 //SetMenuPrompt : Set the user prompt. Even " " is allowed, but "" uses default value
@@ -268,10 +320,75 @@ func (mo *menuOptions) SetRunTimeErrMsgsPause(val bool) {
 	mo.runTimeErrMsgsPause = val
 }
 
+//SetSuggestKeys : if true a mistyped Menu Key is answered with a
+//"Did you mean" list of the closest Keys of the menu.
+func (mo *menuOptions) SetSuggestKeys(val bool) {
+	mo.suggestKeys = val
+}
+
+//SetSuggestRun : if true, and there is a single best suggestion for a
+//mistyped Menu Key, the user is offered to run it with a one-key confirmation.
+//Has no effect if suggestKeys is false.
+func (mo *menuOptions) SetSuggestRun(val bool) {
+	mo.suggestRun = val
+}
+
+//SetConfirmBypass : if true Menu Entries set with SetEntryConfirm() run without
+//asking, for scripted or non-interactive use of the menus.
+func (mo *menuOptions) SetConfirmBypass(val bool) {
+	mo.confirmBypass = val
+}
+
+//SetLineEditor : if true input is read with a line editor, with cursor movement,
+//history and Key completion, when the program runs in a terminal that allows it.
+func (mo *menuOptions) SetLineEditor(val bool) {
+	mo.lineEditor = val
+}
+
+//SetFullScreen : if true Menus run full screen, in the terminal's alternate screen,
+//when the program runs in a terminal that allows it. Takes effect the next time
+//a Menu is Start()-ed while no other Menu is running.
+func (mo *menuOptions) SetFullScreen(val bool) {
+	mo.fullScreen = val
+}
+
+//SetRenderer : Sets the Renderer showing all Menus that don't have their own,
+//nil for the classic layout.
+func (mo *menuOptions) SetRenderer(r Renderer) {
+	mo.renderer = r
+}
+
 //Menu : Structure holding a menu's fields
 type Menu struct {
 	Title string //use this or GetID for use in switch statements
 	//private
+	//titleFunc : if set, evaluated at each display instead of showing Title
+	titleFunc func() string
+	//crumbPrefix : shown in front of the breadcrumbs, used by Wizard for its progress
+	crumbPrefix string
+	//lastChoice : ID of the last entry run (not the Break Item) since Start()
+	lastChoice string
+	//defaultID, defaultTimeout : see SetDefaultChoice()
+	defaultID      string
+	defaultTimeout time.Duration
+	//blankPolicy : see SetBlankInput()
+	blankPolicy BlankInput
+	//singleKey : see SetSingleKey()
+	singleKey bool
+	//renderer : see SetRenderer()
+	renderer Renderer
+	//columns : see SetColumns()
+	columns int
+	//pageSize, page : see SetPageSize(), the page shown, from 0
+	pageSize, page int
+	//filter : the texts typed to filter a paged Menu, filtered the ids matching
+	//each text and those before it, see filterKeys()
+	filter   []string
+	filtered [][]string
+	//selectedKey, listTop : the highlighted entry and the first line of the entry
+	//list shown in full screen mode
+	selectedKey string
+	listTop     int
 	//skipFunctionNotification : if a Menu Entry func() starts a menu directly with <menuvar>.Start()
 	//the menu is treated as a function and not a submenu (one should be Using
 	//<menuvar>.AddSubMenu to get that functionality). This means that the menu
@@ -282,8 +399,15 @@ type Menu struct {
 	//it will be reset to false after the exit from the function, or the menu is
 	//normally closed. No need to use if isChooseOne is set, those menus auto-manage.
 	skipFunctionNotification bool
-	entries                  menuEntries
-	finalized                bool
+	//autoKeys : set with SetAutoKeys(). If not AutoKeyNone the Keys given to
+	//AddMenuEntry, AddSubMenu, ChangeMenuEntry etc. are stable IDs and the Key
+	//the user types is assigned by finalize in display order.
+	autoKeys     AutoKeyStyle
+	autoAlphabet []rune
+	entries      menuEntries
+	//entrySeq : counts entries added, gives each entry its insertion order
+	entrySeq  int
+	finalized bool
 	//id : each NewMenu is automatically numbered starting at -1 and
 	//decreasing. One can call GetID() to retrieve them or use
 	//SetID() to set them to values of your own choice.
@@ -297,12 +421,26 @@ type Menu struct {
 	//the "cancel" option. func() can, of course, be added to break indicators too.
 	//It will function to end the menu if isChooseOne value is dynamically re-set
 	isChooseOne, isMainMenu bool
-	isModified, isRunning   bool
-	killThisMenu            bool
+	//checklist : if not nil the Menu is a multi-select checklist, see SetChecklist()
+	checklist *checklistSettings
+	//radioGroups : bound variables of the Menu's radio groups, see AddRadio()
+	radioGroups           map[string]*radioGroup
+	isModified, isRunning bool
+	killThisMenu          bool
+	//closeRequested : set by Close(), exits the scan loop after the current func()
+	closeRequested bool
+	//keyMap : built by finalize, maps the Key the user types to the
+	//menu.entries map key (the entry's ID, or breakIndicator)
+	keyMap map[string]string
 	//quitValue : super important. The key to be used to quit the menu.
 	quitValue   string
 	parent      *Menu
 	reverseSort bool
+	//sections : titled groups of entries in display order, see AddSection()
+	sections []*menuSection
+	//sortOrder, sortFunc : how finalize orders the entries, see SetSortOrder()
+	sortOrder SortOrder
+	sortFunc  func(a, b EntryInfo) bool
 	//sortkeys - quote:	When iterating over a map with a range loop, the iteration order is not specified
 	//and is not guaranteed to be the same from one iteration to the next.
 	//For a stable iteration order one must maintain a separate data structure that specifies that order.
@@ -323,7 +461,11 @@ func (menu *Menu) String() string {
 		parentName = fmt.Sprintf("'%s', ID: %d", menu.parent.Title, menu.parent.id)
 	}
 	unsortedEntries := ">> menu entries (unsorted):\n"
-	for _, e := range menu.entries {
+	for id, e := range menu.entries {
+		if menu.autoKeys != AutoKeyNone && id != breakIndicator {
+			unsortedEntries = unsortedEntries + fmt.Sprintf(ef, id, e.hint)
+			continue
+		}
 		unsortedEntries = unsortedEntries + fmt.Sprintf(ef, e.value, e.hint)
 	}
 
@@ -331,7 +473,13 @@ func (menu *Menu) String() string {
 		fmt.Sprintf(f, "ID", menu.id) + "\n" +
 		fmt.Sprintf(f, "Break Value", "'"+menu.quitValue+"'") + "\n" +
 		fmt.Sprintf(f, "ChooseOne Menu", menu.isChooseOne) + "\n" +
-		fmt.Sprintf(f, "Sort Ascending", menu.reverseSort) + "\n" +
+		fmt.Sprintf(f, "Sort Ascending", !menu.reverseSort) + "\n" +
+		fmt.Sprintf(f, "Sort Order", menu.sortOrder) + "\n" +
+		fmt.Sprintf(f, "Auto Keys", menu.autoKeys) + "\n" +
+		fmt.Sprintf(f, "Blank Input", menu.blankPolicy) + "\n" +
+		fmt.Sprintf(f, "Single Key", menu.singleKey) + "\n" +
+		fmt.Sprintf(f, "Columns", menu.columns) + "\n" +
+		fmt.Sprintf(f, "Page Size", menu.pageSize) + "\n" +
 		fmt.Sprintf(f, "Parent Menu", parentName) + "\n" +
 		unsortedEntries
 }
@@ -345,11 +493,633 @@ func (menu *Menu) SetChooseOne(val bool) {
 //SortAscending : Default. Menu entries will be sorted in ascending order
 func (menu *Menu) SortAscending() {
 	menu.reverseSort = false
+	menu.isModified = menu.finalized
 }
 
 //SortDescending : Menu entries will be sorted in descending order
 func (menu *Menu) SortDescending() {
 	menu.reverseSort = true
+	menu.isModified = menu.finalized
+}
+
+//AutoKeyStyle : the kind of Keys an auto keyed Menu assigns, see Menu.SetAutoKeys()
+type AutoKeyStyle int
+
+const (
+	//AutoKeyNone : Default. Entries are typed with the Key they were added with
+	AutoKeyNone AutoKeyStyle = iota
+	//AutoKeyNumeric : Keys 1, 2, 3 ... N
+	AutoKeyNumeric
+	//AutoKeyAlpha : Keys a, b, ... z, aa, ab ...
+	AutoKeyAlpha
+	//AutoKeyCustom : Keys built from the alphabet set with Menu.SetAutoKeyAlphabet()
+	AutoKeyCustom
+)
+
+func (aks AutoKeyStyle) String() string {
+	return enumString("AutoKeyStyle", int(aks), "AutoKeyNone", "AutoKeyNumeric", "AutoKeyAlpha", "AutoKeyCustom")
+}
+
+//enumString : internal use, the name of value i of an enumeration, Type(i) if
+//there is no such value
+func enumString(typeName string, i int, names ...string) string {
+	if i < 0 || i >= len(names) {
+		return fmt.Sprintf("%s(%d)", typeName, i)
+	}
+	return names[i]
+}
+
+//SetAutoKeys : For menus generated from data. If style is not AutoKeyNone the Keys
+//sent in to AddMenuEntry, AddSubMenu, ChangeMenuEntry, RemoveMenuEntry etc. are
+//stable IDs and the Key the user types is assigned automatically, in display order,
+//skipping the menu Break Value and the kill phrase. If insertionOrder is true the
+//Menu is set to OrderInsertion, otherwise its SetSortOrder() setting is kept. Both
+//set the same order, so of SetAutoKeys(style, true) and SetSortOrder() the one
+//called last wins. Use EntryKey(id) to find the Key assigned to an ID.
+func (menu *Menu) SetAutoKeys(style AutoKeyStyle, insertionOrder bool) error {
+	if style == AutoKeyCustom && len(menu.autoAlphabet) == 0 {
+		errmsg := warn + fmt.Sprintf("SetAutoKeys method: Menu '%s', AutoKeyCustom requested but no alphabet set, use SetAutoKeyAlphabet().", menu.Title)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.autoKeys = style
+	if insertionOrder {
+		menu.sortOrder = OrderInsertion
+	}
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//SetAutoKeyAlphabet : sets the characters an AutoKeyCustom Menu builds its Keys from,
+//for example "asdfjkl;" for home row keys. Also sets the Menu to AutoKeyCustom.
+func (menu *Menu) SetAutoKeyAlphabet(alphabet string) error {
+	var errmsg string
+	seen := make(map[rune]bool)
+	for _, r := range alphabet {
+		if seen[r] || strings.ContainsRune(trimString, r) {
+			errmsg = warn + fmt.Sprintf("SetAutoKeyAlphabet method: Menu '%s', alphabet '%s' has a duplicate or blank character '%c'. Alphabet not set.", menu.Title, alphabet, r)
+			break
+		}
+		seen[r] = true
+	}
+	if alphabet == "" {
+		errmsg = warn + fmt.Sprintf("SetAutoKeyAlphabet method: Menu '%s', empty alphabet sent in. Alphabet not set.", menu.Title)
+	}
+	if errmsg != "" {
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.autoAlphabet = []rune(alphabet)
+	menu.autoKeys = AutoKeyCustom
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//EntryKey : returns the Key the user types for the entry with the given ID. For an
+//auto keyed Menu the Keys are assigned in display order and so may change when
+//entries are added or removed; for other menus the ID is the Key.
+func (menu *Menu) EntryKey(id string) (string, error) {
+	var err error
+	if !menu.finalized {
+		err = menu.finalize()
+	} else if menu.isModified {
+		err = menu.reSet()
+	}
+	if err != nil {
+		return "", err
+	}
+	entry, ok := menu.entries[id]
+	if !ok || id == breakIndicator {
+		errmsg := warn + fmt.Sprintf("EntryKey method: Menu '%s', entry ID '%s' does not exist.", menu.Title, id)
+		alertUser(&errmsg)
+		return "", errors.New(errmsg)
+	}
+	return entry.value, nil
+}
+
+//assignKeys : internal use, called by finalize once menu.sortKeys is in display order.
+//Gives auto keyed entries their Keys and rebuilds menu.keyMap.
+func (menu *Menu) assignKeys() {
+	alphabet := menu.keyAlphabet()
+	menu.keyMap = make(map[string]string, len(menu.sortKeys))
+	n := 0
+	for _, id := range menu.sortKeys {
+		entry := menu.entries[id]
+		for id != breakIndicator && menu.autoKeys != AutoKeyNone && !entry.fixedKey {
+			n++
+			if key := autoKey(n, alphabet); !menu.isReservedKey(key) {
+				entry.value = key
+				break
+			}
+		}
+		menu.keyMap[entry.value] = id
+	}
+}
+
+//keyAlphabet : internal use, the alphabet of an auto keyed Menu, nil for numbers
+func (menu *Menu) keyAlphabet() []rune {
+	switch menu.autoKeys {
+	case AutoKeyAlpha:
+		return []rune(alphaKeyAlphabet)
+	case AutoKeyCustom:
+		return menu.autoAlphabet
+	}
+	return nil
+}
+
+//lastAutoKey : internal use, the Key assignKeys() gives the last entry of an auto
+//keyed Menu
+func (menu *Menu) lastAutoKey() string {
+	alphabet := menu.keyAlphabet()
+	key, n := "", 0
+	for id, entry := range menu.entries {
+		if id == breakIndicator || entry.fixedKey {
+			continue
+		}
+		for {
+			n++
+			if key = autoKey(n, alphabet); !menu.isReservedKey(key) {
+				break
+			}
+		}
+	}
+	return key
+}
+
+//isReservedKey : internal use, true if auto keys must skip key: the Break Value,
+//the kill phrase, the paging commands of a paged Menu and the commands of a checklist
+func (menu *Menu) isReservedKey(key string) bool {
+	return key == menu.quitValue || key == MenuOptions.killPhrase || (menu.pageSize > 0 && isPagingKey(key)) ||
+		(menu.checklist != nil && menu.isChecklistKey(key))
+}
+
+//autoKey : internal use. Returns the n'th (starting at 1) Key of an alphabet,
+//counting a, b ... z, aa, ab ... or, for a nil alphabet, the number n.
+func autoKey(n int, alphabet []rune) string {
+	if len(alphabet) == 0 {
+		return strconv.Itoa(n)
+	}
+	var key []rune
+	for ; n > 0; n = (n - 1) / len(alphabet) {
+		key = append([]rune{alphabet[(n-1)%len(alphabet)]}, key...)
+	}
+	return string(key)
+}
+
+//SortOrder : how a Menu orders its entries, see Menu.SetSortOrder()
+type SortOrder int
+
+const (
+	//OrderKey : Default. Entries are sorted by Key as plain strings, so "10" comes before "2"
+	OrderKey SortOrder = iota
+	//OrderInsertion : Entries are shown in the order they were added
+	OrderInsertion
+	//OrderWeight : Entries are sorted by the weight set with SetEntryWeight(), lightest
+	//first, equal weights by Key
+	OrderWeight
+	//OrderNatural : Entries are sorted by Key, numbers inside Keys compared by value,
+	//so "2" comes before "10" and "item9" before "item10"
+	OrderNatural
+	//OrderHint : Entries are sorted by their hint text, equal hints by Key
+	OrderHint
+	//OrderCustom : Entries are sorted by the func() set with SetSortFunc()
+	OrderCustom
+)
+
+func (so SortOrder) String() string {
+	return enumString("SortOrder", int(so), "OrderKey", "OrderInsertion", "OrderWeight", "OrderNatural", "OrderHint", "OrderCustom")
+}
+
+//EntryInfo : the description of a Menu Entry handed to a SetSortFunc() comparator.
+//ID is the Key the entry was added with (for auto keyed menus the stable ID,
+//the typed Key is not yet assigned when sorting).
+type EntryInfo struct {
+	ID      string
+	Hint    string
+	Section string
+	Weight  int
+	//Order : insertion order of the entry, starting at 1
+	Order int
+}
+
+//SetSortOrder : Set how the Menu orders its entries, default is OrderKey. SortAscending()
+//and SortDescending() still apply on top of the order, and the Break Item stays
+//pinned to the bottom (top if descending). OrderCustom needs SetSortFunc().
+func (menu *Menu) SetSortOrder(order SortOrder) error {
+	if order == OrderCustom && menu.sortFunc == nil {
+		errmsg := warn + fmt.Sprintf("SetSortOrder method: Menu '%s', OrderCustom requested but no comparator set, use SetSortFunc(). Order not changed.", menu.Title)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.sortOrder = order
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//SetSortFunc : Set a custom comparator for the Menu entries, less reports whether
+//a should be displayed before b. Also sets the Menu to OrderCustom.
+func (menu *Menu) SetSortFunc(less func(a, b EntryInfo) bool) error {
+	if less == nil {
+		errmsg := warn + fmt.Sprintf("SetSortFunc method: Menu '%s', nil comparator sent in. Order not changed.", menu.Title)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.sortFunc = less
+	menu.sortOrder = OrderCustom
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//SetEntryWeight : Set the weight of a Menu Entry for OrderWeight menus, lighter
+//entries are displayed first. Entries default to weight 0.
+func (menu *Menu) SetEntryWeight(key string, weight int) error {
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	entry, ok := menu.entries[key]
+	if !ok || key == breakIndicator {
+		errmsg := warn + fmt.Sprintf("SetEntryWeight method: Menu '%s', entry key '%s' does not exist. Weight not set.", menu.Title, key)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	entry.weight = weight
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//sortEntries : internal use, sorts the menu.entries map keys in ids
+//according to the Menu's sortOrder.
+func (menu *Menu) sortEntries(ids []string) {
+	if menu.sortOrder == OrderKey {
+		sort.Strings(ids)
+		return
+	}
+	info := func(id string) EntryInfo {
+		e := menu.entries[id]
+		return EntryInfo{ID: id, Hint: e.hint, Section: e.section, Weight: e.weight, Order: e.seq}
+	}
+	var less func(a, b EntryInfo) bool
+	switch menu.sortOrder {
+	case OrderInsertion:
+		less = func(a, b EntryInfo) bool { return a.Order < b.Order }
+	case OrderWeight:
+		less = func(a, b EntryInfo) bool {
+			if a.Weight != b.Weight {
+				return a.Weight < b.Weight
+			}
+			return a.ID < b.ID
+		}
+	case OrderNatural:
+		less = func(a, b EntryInfo) bool { return naturalLess(a.ID, b.ID) }
+	case OrderHint:
+		less = func(a, b EntryInfo) bool {
+			if a.Hint != b.Hint {
+				return a.Hint < b.Hint
+			}
+			return a.ID < b.ID
+		}
+	case OrderCustom:
+		less = menu.sortFunc
+	}
+	//start from a known order, the map gives the ids in random order
+	sort.Strings(ids)
+	sort.SliceStable(ids, func(i, j int) bool { return less(info(ids[i]), info(ids[j])) })
+}
+
+//naturalLess : internal use. Compares strings treating runs of digits as numbers,
+//"item9" < "item10". Equal numbers with different leading zeros sort shortest first.
+func naturalLess(a, b string) bool {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return a[i] < b[j]
+			}
+			i++
+			j++
+			continue
+		}
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		na, nb := strings.TrimLeft(a[si:i], "0"), strings.TrimLeft(b[sj:j], "0")
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		if na != nb {
+			return na < nb
+		}
+		if i-si != j-sj {
+			return i-si < j-sj
+		}
+	}
+	return len(a)-i < len(b)-j
+}
+
+//SetEntryEnabled : enabled is evaluated every time the Menu is displayed. While it
+//returns false the entry is shown, with reason, but can not be selected. Typing its
+//Key prints the reason instead of running the entry's func(). nil always enables.
+func (menu *Menu) SetEntryEnabled(key string, enabled func() bool, reason string) error {
+	entry, err := menu.predicateEntry("SetEntryEnabled", key)
+	if err != nil {
+		return err
+	}
+	valueClean(&reason, &emptyString, vIgnore, func() {})
+	entry.enabled = enabled
+	entry.disabledReason = reason
+	return nil
+}
+
+//SetEntryVisible : visible is evaluated every time the Menu is displayed. While it
+//returns false the entry is not displayed and its Key is not matched at all.
+//nil always shows the entry.
+func (menu *Menu) SetEntryVisible(key string, visible func() bool) error {
+	entry, err := menu.predicateEntry("SetEntryVisible", key)
+	if err != nil {
+		return err
+	}
+	entry.visible = visible
+	return nil
+}
+
+//predicateEntry : internal use, validation shared by the entry predicate setters.
+//The Break Item can not be disabled or hidden, the menu must always be quittable.
+func (menu *Menu) predicateEntry(methodName, key string) (*menuEntry, error) {
+	var errmsg string
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	entry, ok := menu.entries[key]
+	switch {
+	case key == breakIndicator || (ok && entry == menu.entries[breakIndicator]):
+		errmsg = warn + fmt.Sprintf("%s method: Menu '%s', key '%s' is the menu break key, not allowed.", methodName, menu.Title, key)
+	case !ok:
+		errmsg = warn + fmt.Sprintf("%s method: Menu '%s', entry key '%s' does not exist.", methodName, menu.Title, key)
+	}
+	if errmsg != "" {
+		alertUser(&errmsg)
+		return nil, errors.New(errmsg)
+	}
+	return entry, nil
+}
+
+//refreshEntryStates : internal use, evaluates the entry predicates, called by displayMenu
+func (menu *Menu) refreshEntryStates() {
+	for _, e := range menu.entries {
+		e.hidden = e.visible != nil && !e.visible()
+		e.disabled = e.enabled != nil && !e.enabled()
+	}
+	menu.settleRadioGroups()
+}
+
+//SetTitleFunc : titleFunc is evaluated every time the Menu (or one of its SubMenus)
+//is displayed and shown instead of Title, for live values like "Logged in as X".
+//nil goes back to showing Title.
+func (menu *Menu) SetTitleFunc(titleFunc func() string) {
+	menu.titleFunc = titleFunc
+}
+
+//SetEntryHintFunc : hintFunc is evaluated every time the Menu is displayed and shown
+//instead of the entry's hint, for live values like "Cache: 3 items". Unlike
+//ChangeMenuEntry this does not re-sort or re-validate the Menu. nil goes back
+//to the static hint, which is also the hint used by OrderHint.
+func (menu *Menu) SetEntryHintFunc(key string, hintFunc func() string) error {
+	entry, err := menu.liveEntry("SetEntryHintFunc", key)
+	if err != nil {
+		return err
+	}
+	entry.hintFunc = hintFunc
+	return nil
+}
+
+//SetEntryBadge : badgeFunc is evaluated every time the Menu is displayed and its
+//result, a count or status marker, is shown in a dedicated column after the hint.
+//An empty result shows no badge. nil removes the badge.
+func (menu *Menu) SetEntryBadge(key string, badgeFunc func() string) error {
+	entry, err := menu.liveEntry("SetEntryBadge", key)
+	if err != nil {
+		return err
+	}
+	entry.badgeFunc = badgeFunc
+	return nil
+}
+
+//liveEntry : internal use, finds the entry for the render time setters,
+//the Break Item can be given by its Break Value.
+func (menu *Menu) liveEntry(methodName, key string) (*menuEntry, error) {
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	entry, ok := menu.entries[key]
+	if !ok && key != "" && key == menu.quitValue {
+		entry, ok = menu.entries[breakIndicator]
+	}
+	if !ok {
+		errmsg := warn + fmt.Sprintf("%s method: Menu '%s', entry key '%s' does not exist.", methodName, menu.Title, key)
+		alertUser(&errmsg)
+		return nil, errors.New(errmsg)
+	}
+	return entry, nil
+}
+
+//displayTitle : internal use, the Title as it is displayed right now
+func (menu *Menu) displayTitle() string {
+	if menu.titleFunc != nil {
+		return menu.titleFunc()
+	}
+	return menu.Title
+}
+
+//displayHint : internal use, the hint as it is displayed right now
+func (entry *menuEntry) displayHint() string {
+	hint := entry.hint
+	if entry.hintFunc != nil {
+		hint = entry.hintFunc()
+	}
+	if entry.decorate != nil {
+		hint = entry.decorate(hint)
+	}
+	return hint
+}
+
+//isBracketed : internal use, if true the entry's func() output is wrapped in
+//func brackets. SubMenus and state entries flow straight back to the menu.
+func (entry *menuEntry) isBracketed() bool {
+	return !entry.isSubMenuEntry && !entry.isStateEntry
+}
+
+//displayBadge : internal use, the badge as it is displayed right now
+func (entry *menuEntry) displayBadge() string {
+	if entry.badgeFunc != nil {
+		return entry.badgeFunc()
+	}
+	return ""
+}
+
+//ConfirmMode : how a Menu Entry asks for confirmation, see Menu.SetEntryConfirm()
+type ConfirmMode int
+
+const (
+	//ConfirmNone : Default. The entry runs straight away
+	ConfirmNone ConfirmMode = iota
+	//ConfirmYesNo : the user must answer 'y' to run the entry
+	ConfirmYesNo
+	//ConfirmRetypeKey : the user must type the entry's Key again, for dangerous actions
+	ConfirmRetypeKey
+)
+
+//SetEntryConfirm : The entry asks question (if empty, "Are you sure?") before its
+//func() runs. Any other answer than the expected one returns to the menu without
+//func brackets or pause. MenuOptions.SetConfirmBypass(true) skips all confirmations.
+func (menu *Menu) SetEntryConfirm(key string, mode ConfirmMode, question string) error {
+	entry, err := menu.predicateEntry("SetEntryConfirm", key)
+	if err != nil {
+		return err
+	}
+	valueClean(&question, &defConfirmQuestion, vIgnore, func() {})
+	entry.confirm = mode
+	entry.confirmQuestion = question
+	return nil
+}
+
+//confirmed : internal use, asks the entry's confirmation, if any. key is the Key the user typed.
+func (entry *menuEntry) confirmed(key string) bool {
+	if entry.confirm == ConfirmNone || MenuOptions.confirmBypass {
+		return true
+	}
+	switch entry.confirm {
+	case ConfirmRetypeKey:
+		fmt.Printf("%s Type '%s' again to confirm: ", entry.confirmQuestion, key)
+	default:
+		fmt.Printf("%s [y/N]: ", entry.confirmQuestion)
+	}
+	answer, ok := readLine()
+	if !ok {
+		return false
+	}
+	answer = strings.Trim(answer, trimString)
+	if entry.confirm == ConfirmRetypeKey {
+		return answer == key
+	}
+	return answer == "y" || answer == "Y"
+}
+
+//menuSection : a titled group of Menu entries, an empty title displays
+//as a plain separator line
+type menuSection struct {
+	id, title string
+}
+
+//AddSection : Adds a section to the Menu. Sections are displayed in the order they
+//are added, after the entries that have no section, and the menu sort order applies
+//within each section. The title is a non-selectable header line, an empty title
+//gives a plain separator line. Use AddSectionEntry() or SetEntrySection() to fill it.
+func (menu *Menu) AddSection(id, title string) error {
+	var errmsg string
+	valueClean(&id, &emptyString, vInBlock, func() {
+		errmsg = warn + fmt.Sprintf("AddSection method: Menu '%s', empty section ID sent in (title was '%s'), section not added.", menu.Title, title)
+		alertUser(&errmsg)
+	})
+	if id == "" {
+		return errors.New(errmsg)
+	}
+	if menu.sectionIndex(id) >= 0 {
+		errmsg = warn + fmt.Sprintf("AddSection method: Menu '%s', section '%s' already exists, section not added.", menu.Title, id)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	valueClean(&title, &emptyString, vIgnore, func() {})
+	menu.sections = append(menu.sections, &menuSection{id: id, title: title})
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//RemoveSection : Removes a section from the Menu. Its entries are not removed,
+//they are displayed with the entries that have no section.
+func (menu *Menu) RemoveSection(id string) error {
+	valueClean(&id, &emptyString, vIgnore, func() {})
+	idx := menu.sectionIndex(id)
+	if idx < 0 {
+		errmsg := warn + fmt.Sprintf("RemoveSection method: Menu '%s', section '%s' does not exist, nothing to remove.", menu.Title, id)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.sections = append(menu.sections[:idx], menu.sections[idx+1:]...)
+	for _, e := range menu.entries {
+		if e.section == id {
+			e.section = ""
+		}
+	}
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//AddSectionEntry : Same as AddMenuEntry but the entry is displayed in section.
+func (menu *Menu) AddSectionEntry(section, aval, ahint string, afunc func()) error {
+	valueClean(&section, &emptyString, vIgnore, func() {})
+	if section != "" && menu.sectionIndex(section) < 0 {
+		errmsg := warn + fmt.Sprintf("AddSectionEntry method: Menu '%s', section '%s' does not exist, use AddSection(). Entry '%s' not added.", menu.Title, section, aval)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	if err := menu.AddMenuEntry(aval, ahint, afunc); err != nil {
+		return err
+	}
+	return menu.SetEntrySection(strings.Trim(aval, trimString), section)
+}
+
+//SetEntrySection : Moves a Menu Entry into a section, "" moves it out of any section.
+func (menu *Menu) SetEntrySection(key, section string) error {
+	const methodName = "SetEntrySection method: "
+	var errmsg string
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	valueClean(&section, &emptyString, vIgnore, func() {})
+	entry, ok := menu.entries[key]
+	switch {
+	case !ok || key == breakIndicator:
+		errmsg = warn + fmt.Sprintf(methodName+"Menu '%s', entry key '%s' does not exist.", menu.Title, key)
+	case section != "" && menu.sectionIndex(section) < 0:
+		errmsg = warn + fmt.Sprintf(methodName+"Menu '%s', section '%s' does not exist, use AddSection().", menu.Title, section)
+	}
+	if errmsg != "" {
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	entry.section = section
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//sectionIndex : internal use, returns the display position of a section or -1
+func (menu *Menu) sectionIndex(id string) int {
+	for i, sec := range menu.sections {
+		if sec.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+//sectionHeader : internal use, returns the line displayed above a section's entries
+func (menu *Menu) sectionHeader(id string) string {
+	idx := menu.sectionIndex(id)
+	switch {
+	case idx < 0:
+		return ""
+	case menu.sections[idx].title == "":
+		return separatorLine
+	}
+	return fmt.Sprintf(sectionFormat, menu.sections[idx].title)
+}
+
+//groupBySection : internal use, stable re-ordering of sorted entry ids so
+//entries without section come first, followed by each section in turn.
+func (menu *Menu) groupBySection(ids []string) {
+	if len(menu.sections) == 0 {
+		return
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return menu.sectionIndex(menu.entries[ids[i]].section) < menu.sectionIndex(menu.entries[ids[j]].section)
+	})
 }
 
 //SkipFunctionNotification : Niche use, but very useful when needed. if a Menu Entry func()
@@ -374,6 +1144,7 @@ func NewMenu(title string) (result *Menu) {
 		Title: title,
 		//private
 		skipFunctionNotification: false,
+		autoKeys:                 AutoKeyNone,
 		entries:                  make(menuEntries),
 		finalized:                false,
 		id:                       getmenuID(),
@@ -382,24 +1153,72 @@ func NewMenu(title string) (result *Menu) {
 		isModified:               false,
 		isRunning:                false,
 		killThisMenu:             false,
+		keyMap:                   make(map[string]string),
 		parent:                   nil,
 		quitValue:                "",
 		reverseSort:              false,
+		sortOrder:                OrderKey,
 		validateKeys:             make(validateKey),
 	}
-	if len(allMenus) == 0 {
+	//the first Menu created is the MAIN MENU, a Form's Menu never is
+	if !hasMainMenu() {
 		result.isMainMenu = true
 	}
 	allMenus = append(allMenus, result)
 	return
 }
 
+//hasMainMenu : internal use, true if one of allMenus is the MAIN MENU
+func hasMainMenu() bool {
+	for _, m := range allMenus {
+		if m.isMainMenu {
+			return true
+		}
+	}
+	return false
+}
+
 //menuEntry : holds the Menu Key, description, and entry's func()
 type menuEntry struct {
+	//value : the Key the user types. For auto keyed menus this is assigned
+	//by finalize and the entry's ID is its menu.entries map key.
 	value string
 	hint  string
+	//seq : insertion order of the entry in its Menu
+	seq int
+	//weight : used by OrderWeight, see SetEntryWeight()
+	weight int
+	//section : ID of the Menu section the entry is displayed in, "" for none
+	section string
+	//enabled, visible : predicates evaluated at each displayMenu, the results
+	//are kept in disabled and hidden until the next display. nil means true.
+	enabled, visible func() bool
+	disabledReason   string
+	disabled, hidden bool
+	//hintFunc, badgeFunc : evaluated at each displayMenu, see SetEntryHintFunc()
+	//and SetEntryBadge(). Changing what they return never re-finalizes the Menu.
+	hintFunc, badgeFunc func() string
 	//isSubMenuEntry : internal use for indicating submenus
 	isSubMenuEntry bool
+	//isStateEntry : toggle, cycle and counter entries, their doRun changes a
+	//bound value and the menu is redisplayed without func() brackets or pause
+	isStateEntry bool
+	//decorate : shows a state entry's current value around its hint
+	decorate func(hint string) string
+	//checked, checkValue : selection mark and value of a checklist item
+	checked    bool
+	checkValue interface{}
+	//radioGroup, radioValue : name of the entry's radio group and the value
+	//it sets the group's bound variable to, see AddRadio()
+	radioGroup, radioValue string
+	//confirm, confirmQuestion : asked before doRun, see SetEntryConfirm()
+	confirm         ConfirmMode
+	confirmQuestion string
+	//output : where the output of doRun goes, see SetEntryOutput()
+	output OutputMode
+	//fixedKey : the entry keeps its Key in auto keyed menus, for entries the
+	//library adds, like the wizard's back entry
+	fixedKey bool
 	//doRun : any func can be put in here, simply type the func()
 	//in the body. Obviates the need for varieties of func declarations.
 	doRun func()
@@ -486,6 +1305,153 @@ func (ms menuSystem) StartMenuSystem() error {
 func (menu *Menu) setRunning(val bool) {
 	menu.isRunning = val
 	menu.skipFunctionNotification = false
+	menu.closeRequested = false
+}
+
+//SetDefaultChoice : key (an entry's Key, ID for auto keyed menus, or the Break Value) is
+//chosen when the user just presses <RET> in a ChooseOne menu, or in any menu set to
+//SetBlankInput(BlankDefault). For ChooseOne menus, if timeout is more than 0, it is
+//also chosen automatically when the user typed nothing for timeout, with a visible
+//countdown. For menus that may run unattended. "" removes the default.
+func (menu *Menu) SetDefaultChoice(key string, timeout time.Duration) {
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	menu.defaultID = key
+	menu.defaultTimeout = timeout
+	menu.isModified = menu.finalized
+}
+
+//BlankInput : what a Menu does when the user just presses <RET>, see Menu.SetBlankInput()
+type BlankInput int
+
+const (
+	//BlankInvalid : Default. Blank input is not a valid menu choice, except that a
+	//ChooseOne menu with a default choice takes it.
+	BlankInvalid BlankInput = iota
+	//BlankIgnore : Nothing happens, the prompt is shown again
+	BlankIgnore
+	//BlankRedisplay : The menu is displayed again
+	BlankRedisplay
+	//BlankRepeat : The last entry run in this menu is run again, handy for "refresh" entries
+	BlankRepeat
+	//BlankDefault : The entry set with SetDefaultChoice() is run
+	BlankDefault
+)
+
+func (bi BlankInput) String() string {
+	return enumString("BlankInput", int(bi), "BlankInvalid", "BlankIgnore", "BlankRedisplay", "BlankRepeat", "BlankDefault")
+}
+
+//SetBlankInput : Set what the Menu does when the user just presses <RET> at its prompt.
+func (menu *Menu) SetBlankInput(policy BlankInput) {
+	menu.blankPolicy = policy
+}
+
+//blankInput : internal use, the policy in effect for blank input
+func (menu *Menu) blankInput() BlankInput {
+	if menu.blankPolicy == BlankInvalid && menu.isChooseOne && menu.defaultInput() != "" {
+		return BlankDefault
+	}
+	return menu.blankPolicy
+}
+
+//repeatInput : internal use, the Key of the last entry run, "" if it can't be repeated
+func (menu *Menu) repeatInput() string {
+	if entry, ok := menu.entries[menu.lastChoice]; ok && !entry.hidden {
+		return entry.value
+	}
+	return ""
+}
+
+//defaultInput : internal use, the Key the default choice is typed with, "" if there is none
+func (menu *Menu) defaultInput() string {
+	if entry, ok := menu.entries[menu.defaultID]; ok && menu.defaultID != breakIndicator {
+		return entry.value
+	}
+	if menu.defaultID != "" && menu.defaultID == menu.quitValue {
+		return menu.quitValue
+	}
+	return ""
+}
+
+//SetSingleKey : if true, and every Key of the Menu is one character, an entry is chosen
+//as soon as its Key is pressed, without <RET>. Needs a terminal that can be put in raw
+//mode, otherwise, or while counting down to a default choice, input is read as usual.
+//The kill phrase must then also be one character to be typed at this Menu, and an
+//auto keyed Menu must have few enough entries for its Keys to be one character,
+//up to 9 numbers for AutoKeyNumeric. In a paged Menu the text of a '/' filter is
+//typed on a line of its own.
+func (menu *Menu) SetSingleKey(val bool) {
+	menu.singleKey = val
+	menu.isModified = menu.finalized
+}
+
+//singleKeys : internal use, true if every Key the user can type is one character
+func (menu *Menu) singleKeys() bool {
+	for _, id := range menu.sortKeys {
+		if utf8.RuneCountInString(menu.entries[id].value) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+//readChoice : internal use, reads the user's input at the menu prompt, counting down
+//to the default choice if the Menu has a timeout
+func (menu *Menu) readChoice() (string, bool) {
+	def := menu.defaultInput()
+	if screen.active && (def == "" || menu.defaultTimeout <= 0 || !menu.isChooseOne) {
+		if restore, err := makeRaw(os.Stdin); err == nil {
+			defer restore()
+			return menu.navigate(stdinRunes{})
+		}
+	}
+	if menu.singleKey && menu.singleKeys() && (def == "" || menu.defaultTimeout <= 0 || !menu.isChooseOne) {
+		if key, ok, isRaw := readKey(); isRaw {
+			if ok && key == filterCmd && menu.pageSize > 0 {
+				//the filter's text is typed on a line of its own
+				fmt.Print(filterCmd)
+				text, ok := (&lineEditor{prompt: filterCmd}).read()
+				return filterCmd + text, ok
+			}
+			return key, ok
+		}
+	}
+	if !menu.isChooseOne || def == "" || menu.defaultTimeout <= 0 {
+		editor := &lineEditor{
+			prompt:   MenuOptions.menuPrompt,
+			history:  promptHistory(menu.historyID()),
+			complete: menu.completions,
+			resized:  menu.redisplay(),
+		}
+		return editor.read()
+	}
+	text, ok, timedOut := readLineTimeout(menu.defaultTimeout, func(left int) string {
+		if left == 0 {
+			return MenuOptions.menuPrompt
+		}
+		return fmt.Sprintf("%s(choosing '%s' in %ds) ", MenuOptions.menuPrompt, def, left)
+	})
+	if timedOut {
+		fmt.Println("\n" + MenuOptions.menuPrompt + def)
+		return def, true
+	}
+	return text, ok
+}
+
+//LastChoice : returns the Key (ID for auto keyed menus) of the last Menu Entry the
+//user ran since the Menu was last Start()-ed, "" if none. The Break Item does not
+//count, so for a ChooseOne menu "" means the user canceled.
+func (menu *Menu) LastChoice() string {
+	return menu.lastChoice
+}
+
+//Close : Asks the Menu to exit its scan loop as soon as the currently running
+//Menu Entry func() returns, as if the user had typed the Break Value (but the
+//Break Item's func() is not run). Has no effect if the Menu is not running.
+func (menu *Menu) Close() {
+	if menu.isRunning {
+		menu.closeRequested = true
+	}
 }
 
 //SetID : Set a Menu's id. By default each NewMenu() gets a negative id.
@@ -642,9 +1608,11 @@ func (menu *Menu) AddMenuEntry(aval string, ahint string, afunc func()) error {
 		return errors.New(errmsg)
 	}
 	valueClean(&ahint, &emptyHint, vIgnore, func() {})
+	menu.entrySeq++
 	menu.entries[aval] = &menuEntry{
 		value:          aval,
 		hint:           ahint,
+		seq:            menu.entrySeq,
 		isSubMenuEntry: false,
 		doRun:          afunc,
 	}
@@ -670,7 +1638,7 @@ func (menu *Menu) RemoveMenuEntry(key string) error {
 		return errors.New(errmsg)
 	}
 
-	if key == menu.entries[breakIndicator].value {
+	if key == breakIndicator || (menu.autoKeys == AutoKeyNone && key == menu.entries[breakIndicator].value) {
 		errmsg = warn + fmt.Sprintf(methodName+"Menu '%s', key '%s' is the menu break key, removal not allowed.", menu.Title, key)
 		alertUser(&errmsg)
 		return errors.New(errmsg)
@@ -704,7 +1672,7 @@ func alertUser(errmsg *string) {
 	if !MenuOptions.runTimeErrMsgsDisplay {
 		return
 	}
-	fmt.Printf("\n" + *errmsg + "\n")
+	fmt.Printf("\n" + strings.Replace(*errmsg, warn, MenuOptions.theme.Warning.Paint(warn), 1) + "\n")
 	if MenuOptions.runTimeErrMsgsPause {
 		WaitForInput(&additionalString)
 	}
@@ -812,6 +1780,12 @@ func (menu *Menu) ChangeMenuEntryFunc(key string, afunc func()) error {
 		return errors.New(errmsg)
 	}
 
+	if entry.isStateEntry {
+		errmsg = warn + methodName + fmt.Sprintf("Menu '%s', key '%s': Attempting to change a toggle, cycle or counter func(), use its onChange instead.", menu.Title, key) + endMsg
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+
 	//ready to apply changes...
 	entry.doRun = afunc
 	menu.isModified = menu.finalized
@@ -820,8 +1794,26 @@ func (menu *Menu) ChangeMenuEntryFunc(key string, afunc func()) error {
 
 //transferEntryFields : internal func() to pass any important values when changing a Menu Entry
 func (menu *Menu) transferEntryFields(newkey, oldkey string) {
-	//right now only 1 "property" on Menu Entries, make sure they are transferred
 	menu.entries[newkey].isSubMenuEntry = menu.entries[oldkey].isSubMenuEntry
+	menu.entries[newkey].isStateEntry = menu.entries[oldkey].isStateEntry
+	menu.entries[newkey].decorate = menu.entries[oldkey].decorate
+	menu.entries[newkey].checked = menu.entries[oldkey].checked
+	menu.entries[newkey].checkValue = menu.entries[oldkey].checkValue
+	menu.entries[newkey].radioGroup = menu.entries[oldkey].radioGroup
+	menu.entries[newkey].radioValue = menu.entries[oldkey].radioValue
+	menu.entries[newkey].confirm = menu.entries[oldkey].confirm
+	menu.entries[newkey].confirmQuestion = menu.entries[oldkey].confirmQuestion
+	//a renamed entry keeps its place in insertion and weight order
+	menu.entries[newkey].seq = menu.entries[oldkey].seq
+	menu.entries[newkey].weight = menu.entries[oldkey].weight
+	menu.entries[newkey].section = menu.entries[oldkey].section
+	menu.entries[newkey].enabled = menu.entries[oldkey].enabled
+	menu.entries[newkey].visible = menu.entries[oldkey].visible
+	menu.entries[newkey].disabledReason = menu.entries[oldkey].disabledReason
+	menu.entries[newkey].hintFunc = menu.entries[oldkey].hintFunc
+	menu.entries[newkey].badgeFunc = menu.entries[oldkey].badgeFunc
+	menu.entries[newkey].output = menu.entries[oldkey].output
+	menu.entries[newkey].fixedKey = menu.entries[oldkey].fixedKey
 }
 
 //ChangeMenuTitle : Change a Menu's Title, really only useful for dynamic menus. You also have
@@ -850,6 +1842,7 @@ func (menu *Menu) reSet() error {
 		return errors.New(errmsg)
 	}
 	menu.sortKeys = nil
+	menu.filtered = nil
 	menu.finalized = false
 	err := menu.finalize()
 
@@ -890,7 +1883,8 @@ func (menu *Menu) doValidate() (string, error) {
 		return "", errors.New(errmsg)
 	}
 
-	if _, ok2 := menu.validateKeys[MenuOptions.killPhrase]; ok2 {
+	//auto keyed menus never assign the kill phrase, their IDs don't matter
+	if _, ok2 := menu.validateKeys[MenuOptions.killPhrase]; ok2 && menu.autoKeys == AutoKeyNone {
 		errmsg = warn + fmt.Sprintf(killErr, menu.Title, menuStr, menu.entries[MenuOptions.killPhrase].value, MenuOptions.killPhrase)
 		alertUser(&errmsg)
 		return "", errors.New(errmsg)
@@ -900,7 +1894,7 @@ func (menu *Menu) doValidate() (string, error) {
 	//while messages are important information but do not stop menu functionality
 	var report = ""
 
-	if _, ok3 := menu.validateKeys[menu.entries[breakIndicator].value]; ok3 {
+	if _, ok3 := menu.validateKeys[menu.entries[breakIndicator].value]; ok3 && menu.autoKeys == AutoKeyNone {
 		report = report + fmt.Sprintf(keysBreak, menu.Title, menu.entries[breakIndicator].value)
 	}
 
@@ -921,6 +1915,42 @@ func (menu *Menu) doValidate() (string, error) {
 		delete(menu.validateKeys, k)
 	}
 
+	if menu.checklist != nil {
+		report = report + menu.validateChecklist()
+	}
+
+	if menu.pageSize > 0 {
+		report = report + menu.validatePaging()
+	}
+
+	if menu.defaultID != "" && menu.defaultID != menu.quitValue {
+		if _, ok := menu.entries[menu.defaultID]; !ok {
+			report = report + fmt.Sprintf(">> Menu '%s' has a default choice '%s' which is not a Key, default ignored\n", menu.Title, menu.defaultID)
+		}
+	}
+
+	if menu.singleKey {
+		var long []string
+		for k, entry := range menu.entries {
+			if k != breakIndicator && utf8.RuneCountInString(entry.value) != 1 && (menu.autoKeys == AutoKeyNone || entry.fixedKey) {
+				long = append(long, "'"+entry.value+"'")
+			}
+		}
+		if key := menu.lastAutoKey(); menu.autoKeys != AutoKeyNone && utf8.RuneCountInString(key) > 1 {
+			long = append(long, "'"+key+"'")
+		}
+		if utf8.RuneCountInString(menu.quitValue) != 1 {
+			long = append(long, "'"+menu.quitValue+"'")
+		}
+		if len(long) > 0 {
+			sort.Strings(long)
+			report = report + fmt.Sprintf(">> Menu '%s' is set to single key but has the Keys %s which are longer than one character, single key is ignored\n", menu.Title, strings.Join(long, ", "))
+		}
+		if utf8.RuneCountInString(MenuOptions.killPhrase) > 1 {
+			report = report + fmt.Sprintf(">> Menu '%s' is set to single key, the kill phrase '%s' can not be typed there\n", menu.Title, MenuOptions.killPhrase)
+		}
+	}
+
 	//other "report"s can be added, as needed
 
 	return report, nil
@@ -949,25 +1979,30 @@ func (menu *Menu) finalize() error {
 
 	skipKey := menu.entries[breakIndicator].value
 
-	for _, k := range menu.entries {
-		if k.value == skipKey {
+	for id, k := range menu.entries {
+		if id == breakIndicator || (menu.autoKeys == AutoKeyNone && k.value == skipKey) {
 			continue
 		}
-		menu.sortKeys = append(menu.sortKeys, k.value)
+		menu.sortKeys = append(menu.sortKeys, id)
 	}
-	sort.Strings(menu.sortKeys)
-	//ensure quit key is at bottom (or top depending)
-	menu.sortKeys = append(menu.sortKeys, breakIndicator)
+	menu.sortEntries(menu.sortKeys)
 
 	if menu.reverseSort {
-		//probably a better alogorithm somewhere
-		cpy := make([]string, len(menu.sortKeys))
-		copy(cpy, menu.sortKeys)
-		ln := len(cpy) - 1
-		for i := ln; i > -1; i-- {
-			menu.sortKeys[ln-i] = cpy[i]
+		for i, j := 0, len(menu.sortKeys)-1; i < j; i, j = i+1, j-1 {
+			menu.sortKeys[i], menu.sortKeys[j] = menu.sortKeys[j], menu.sortKeys[i]
 		}
 	}
+	//the sort order applies within each section
+	menu.groupBySection(menu.sortKeys)
+
+	//ensure quit key is at bottom (or top depending)
+	if menu.reverseSort {
+		menu.sortKeys = append([]string{breakIndicator}, menu.sortKeys...)
+	} else {
+		menu.sortKeys = append(menu.sortKeys, breakIndicator)
+	}
+
+	menu.assignKeys()
 
 	menu.finalized = true
 	menu.killThisMenu = false
@@ -979,41 +2014,49 @@ func (menu *Menu) finalize() error {
 //displayMenu : Prints the menu to screen, used internally
 func (menu *Menu) displayMenu() {
 
-	var killMsg string
-	if MenuOptions.killPhrase != "" {
-		killMsg = fmt.Sprintf(killTemplate, MenuOptions.killPhrase)
-	} else {
-		killMsg = "=============================="
+	menu.refreshEntryStates()
+
+	if screen.active {
+		menu.drawScreen()
+		return
 	}
 
-	fmt.Println("")
+	menu.render(os.Stdout)
+}
+
+//breadCrumbs : internal use, the Titles of the Menu and its parents
+func (menu *Menu) breadCrumbs() string {
+	return strings.Join(menu.crumbs(), fmt.Sprintf(" %s ", MenuOptions.menuSeparator))
+}
 
-	//get and print menu breadcrumbs, this is the most dangerous part
+//crumbs : internal use, the Titles of the root Menu down to this one
+func (menu *Menu) crumbs() []string {
+	//get menu breadcrumbs, this is the most dangerous part
 	//of this code because here one could go circular. All the current
 	//validation code prevents this from happening.
 	//and I've added an "bailout" check
-	menuSep := fmt.Sprintf(" %s ", MenuOptions.menuSeparator)
-	parentsTitles, checkInfinite, menuParent := "", 0, menu.parent
+	titles, checkInfinite, menuParent, rootMenu := []string{menu.displayTitle()}, 0, menu.parent, menu
 	for menuParent != nil {
-		parentsTitles = menuParent.Title + menuSep + parentsTitles
+		titles = append([]string{menuParent.displayTitle()}, titles...)
+		rootMenu = menuParent
 		menuParent = menuParent.parent
 		checkInfinite++
 		if checkInfinite > len(allMenus) {
 			panic("Menu breadcrumbs appear to be going infinite")
 		}
 	}
-	fmt.Println(parentsTitles + menu.Title)
-
-	fmt.Println("------------------------------")
-
-	//print the menu
-	for _, k := range menu.sortKeys {
-		fmt.Fprintln(alignerLocal, fmt.Sprintf(menuFormat, menu.entries[k].value, menu.entries[k].hint))
+	if rootMenu.crumbPrefix != "" {
+		titles = append([]string{rootMenu.crumbPrefix}, titles...)
 	}
+	return titles
+}
 
-	alignerLocal.Flush()
-	fmt.Println(killMsg)
-	fmt.Print(MenuOptions.menuPrompt)
+//showPrompt : internal use, prompts for another try at the menu prompt. The full
+//screen mode has its own prompt line.
+func showPrompt() {
+	if !screen.active {
+		fmt.Print(MenuOptions.menuPrompt)
+	}
 }
 
 //droppingDown : internal use. If a Menu Entry Start()-s an already open menu
@@ -1052,21 +2095,22 @@ func (menu *Menu) printFuncBrackets(brType bracketSwitch, choice string) {
 
 	getfuncRunnerStr := func(indicator string) string {
 		if MenuOptions.idFuncRunner {
-			return fmt.Sprintf(funcRunnerID, indicator, menu.Title, choice)
+			return fmt.Sprintf(funcRunnerID, indicator, menu.displayTitle(), choice)
 		}
 		return ""
 	}
 
+	brackets := MenuOptions.theme.Brackets
 	switch brType {
 	case bsTop:
-		fmt.Println("\n\n\n" + MenuOptions.funcBracketTop + getfuncRunnerStr(isBegin))
+		fmt.Println("\n\n\n" + brackets.Paint(MenuOptions.funcBracketTop+getfuncRunnerStr(isBegin)))
 	case bsBottom:
-		fmt.Println(MenuOptions.funcBracketBottom + getfuncRunnerStr(isEnd))
+		fmt.Println(brackets.Paint(MenuOptions.funcBracketBottom + getfuncRunnerStr(isEnd)))
 		if MenuOptions.pauseOnOutput {
 			WaitForInput(&emptyString)
 		}
 	case bsPartial:
-		fmt.Println(MenuOptions.funcBracketBottom + getfuncRunnerStr(isEnd))
+		fmt.Println(brackets.Paint(MenuOptions.funcBracketBottom + getfuncRunnerStr(isEnd)))
 	}
 }
 
@@ -1100,14 +2144,30 @@ func (menu *Menu) Start() error {
 		}
 	}
 
+	menu.lastChoice = ""
+	menu.clearFilter()
+	defer enterFullScreen()()
 	menu.displayMenu()
 	menu.setRunning(true)
 	defer menu.setRunning(false)
 
 	var input string
-	for menuScanner.Scan() {
+	for {
+		line, ok := menu.readChoice()
+		if !ok {
+			break
+		}
+		input = strings.Trim(line, " \t")
 
-		input = strings.Trim(menuScanner.Text(), " \t")
+		//a Key that looks like a history command is still a Key
+		if _, isKey := menu.keyMap[input]; !isKey {
+			if recalled, isCommand := historyCommand(menu.historyID(), input); isCommand {
+				showPrompt()
+				continue
+			} else {
+				input = recalled
+			}
+		}
 
 		if input != "" && input == MenuOptions.killPhrase {
 			//master killPhrase was used, stop the menu system
@@ -1115,28 +2175,102 @@ func (menu *Menu) Start() error {
 			killSwitch = true
 			break
 		}
+		addHistory(menu.historyID(), input)
+
+		if input == "" {
+			switch menu.blankInput() {
+			case BlankIgnore:
+				showPrompt()
+				continue
+			case BlankRedisplay:
+				menu.displayMenu()
+				continue
+			case BlankRepeat:
+				if input = menu.repeatInput(); input == "" {
+					fmt.Println("???? Nothing to repeat yet...")
+					showPrompt()
+					continue
+				}
+				fmt.Printf("<repeating '%s'>\n", input)
+			case BlankDefault:
+				input = menu.defaultInput()
+			}
+		}
 
-		if input == menu.quitValue {
+		if menu.checklist != nil {
+			if handled, done := menu.checklistCommand(input); done {
+				break
+			} else if handled {
+				menu.displayMenu()
+				continue
+			}
+		}
+
+		if menu.pagingCommand(input) {
+			menu.displayMenu()
+			continue
+		}
+
+		id, ok := menu.keyMap[input]
+		if ok && menu.entries[id].hidden {
+			//hidden entries don't exist for the user
+			ok = false
+		}
+		if !ok {
+			//mistyped Key, maybe the user accepts a suggestion
+			if input = menu.invalidChoice(input); input == "" {
+				showPrompt()
+				continue
+			}
+			id = menu.keyMap[input]
+		}
+
+		if id == breakIndicator {
 			//The break indicator can also have a func(), so we run it.
 			menu.entries[breakIndicator].doRun()
 			break
 		}
 
-		if elem, ok := menu.entries[input]; ok {
+		if entry := menu.entries[id]; entry.disabled {
+			fmt.Printf("???? '%s' is currently unavailable: %s\n", input, entry.disabledReason)
+			showPrompt()
+			continue
+		}
 
-			if !elem.isSubMenuEntry && !menu.isChooseOne {
+		if menu.checklist != nil {
+			//checklist items are marked, not run
+			menu.toggleChecked(menu.entries[id])
+			menu.displayMenu()
+			continue
+		}
+
+		if !menu.entries[id].confirmed(input) {
+			fmt.Println("<canceled>")
+			menu.displayMenu()
+			continue
+		}
+
+		if elem, ok := menu.entries[id]; ok {
+
+			if elem.isBracketed() && !menu.isChooseOne {
 				menu.printFuncBrackets(bsTop, input)
 			}
 
 			//run the associated menu entry's func()
-			elem.doRun()
+			menu.lastChoice = id
+			elem.run(fmt.Sprintf("%s - choice: '%s'", menu.displayTitle(), input))
 
 			//menu was dynamically changed while the menu was running
 			if menu.isModified {
 				menu.reSet()
 			}
 
-			if menu.killThisMenu || killSwitch || menu.isChooseOne || menu.droppingDown() {
+			//a closing menu still finishes the func() output properly
+			if menu.closeRequested && elem.isBracketed() && !menu.isChooseOne && !killSwitch {
+				menu.printFuncBrackets(bsBottom, input)
+			}
+
+			if menu.killThisMenu || killSwitch || menu.isChooseOne || menu.closeRequested || menu.droppingDown() {
 				break
 			}
 
@@ -1152,29 +2286,154 @@ func (menu *Menu) Start() error {
 				continue
 			}
 
-			if !elem.isSubMenuEntry {
+			if elem.isBracketed() {
 				menu.printFuncBrackets(getSwitch(), input)
 			}
 
 			menu.displayMenu()
-
-		} else {
-			//No.... menu.displayMenu() too annoying to do this in the case of a mis-typed Menu Key
-			fmt.Println("???? " + MenuOptions.menuPrompt + " '" + input + "' is not a valid menu choice...")
-			fmt.Print(MenuOptions.menuPrompt)
 		}
 	}
 	return nil
 }
 
+//invalidChoice : internal use. Informs the user that input is not a Menu Key and,
+//depending on MenuOptions, suggests the closest Keys. Returns the Key the user
+//confirmed to run instead, or "" if there is nothing to run.
+func (menu *Menu) invalidChoice(input string) string {
+	//No.... menu.displayMenu() too annoying to do this in the case of a mis-typed Menu Key
+	fmt.Println("???? " + MenuOptions.menuPrompt + " '" + input + "' is not a valid menu choice...")
+	if !MenuOptions.suggestKeys {
+		return ""
+	}
+	suggestions := menu.suggestKeys(input)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	if !MenuOptions.suggestRun || (len(suggestions) > 1 &&
+		keyDistance(input, suggestions[0]) == keyDistance(input, suggestions[1])) {
+		fmt.Println("     Did you mean: '" + strings.Join(suggestions, "', '") + "' ?")
+		return ""
+	}
+	best := suggestions[0]
+	fmt.Printf("     Did you mean '%s' (%s)? Run it? [y/N]: ", best, menu.entries[menu.keyMap[best]].displayHint())
+	answer, ok := readLine()
+	if !ok {
+		return ""
+	}
+	if answer = strings.Trim(answer, trimString); answer == "y" || answer == "Y" {
+		return best
+	}
+	return ""
+}
+
+//suggestKeys : internal use. Returns up to maxSuggestions Menu Keys that are
+//close to input, best match first. Matching is done on the same Keys
+//the menu matches user input against.
+func (menu *Menu) suggestKeys(input string) []string {
+	const maxSuggestions = 3
+	type candidate struct {
+		key  string
+		dist int
+	}
+	//single characters are only "close" if they differ in case,
+	//longer input may have one edit, and long input two, see keyDistance
+	limit := 1
+	switch n := len([]rune(input)); {
+	case n >= 6:
+		limit = 4
+	case n >= 2:
+		limit = 2
+	}
+
+	var candidates []candidate
+	for _, k := range menu.sortKeys {
+		if menu.entries[k].hidden || menu.entries[k].disabled {
+			continue
+		}
+		key := menu.entries[k].value
+		if d := keyDistance(input, key); d <= limit {
+			candidates = append(candidates, candidate{key, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].key < candidates[j].key
+	})
+
+	result := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		result = append(result, candidates[i].key)
+	}
+	return result
+}
+
+//keyDistance : internal use. Edit distance between two Keys where an insertion,
+//deletion, substitution or transposition of adjacent characters costs 2 and a
+//difference in case only costs 1, so "q" is close to "Q" but not to "x".
+func keyDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	cost := func(x, y rune) int {
+		switch {
+		case x == y:
+			return 0
+		case strings.EqualFold(string(x), string(y)):
+			return 1
+		}
+		return 2
+	}
+	//optimal string alignment distance, needs the two previous rows
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j * 2
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i * 2
+		for j := 1; j <= len(rb); j++ {
+			d := prev[j-1] + cost(ra[i-1], rb[j-1])
+			if v := prev[j] + 2; v < d {
+				d = v
+			}
+			if v := cur[j-1] + 2; v < d {
+				d = v
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if v := prev2[j-2] + 2; v < d {
+					d = v
+				}
+			}
+			cur[j] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
 //WaitForInput : Gives the user opportunity to read output
 //before the menu system continues on. You can use this if you
 //want to make sure the user reads somthing. additional is
 //optional but would give addiational information in the message.
 func WaitForInput(additional *string) {
 	fmt.Println(*additional + "\nPress <RET> to continue...")
-	menuScanner.Scan()
-	menuScanner.Text()
+	readLine()
+}
+
+//WaitForInputTimeout : Same as WaitForInput, but continues by itself after timeout,
+//counting down the seconds left. For prompts that may run unattended.
+func WaitForInputTimeout(additional *string, timeout time.Duration) {
+	const countdown = "Press <RET> to continue (continuing in %ds)... "
+	fmt.Println(*additional)
+	if _, _, timedOut := readLineTimeout(timeout, func(left int) string {
+		if left == 0 {
+			return "Press <RET> to continue... "
+		}
+		return fmt.Sprintf(countdown, left)
+	}); timedOut {
+		fmt.Println("")
+	}
 }
 
 //GetUserInput : Good for basic input, returns the string the User enters.
@@ -1182,21 +2441,120 @@ func WaitForInput(additional *string) {
 //Looping input is possible but probably (?) needs to be made for
 //each type, and would need a passed function to ship each
 //input out to?? I have not tested this func() under all circumstances.
+//Inputs are kept in the history of this prompt, see also GetSecretInput().
 func GetUserInput(prompt string) string {
+	return getInput(prompt, false)
+}
+
+//GetSecretInput : Same as GetUserInput but the input is never kept in the
+//input history, for passwords and the like.
+func GetSecretInput(prompt string) string {
+	return getInput(prompt, true)
+}
+
+//inputPrompt : shown where GetUserInput's input is typed
+const inputPrompt = "=> "
+
+//getInput : internal use, GetUserInput and GetSecretInput
+func getInput(prompt string, secret bool) string {
 	valueClean(&prompt, &emptyString, vIgnore, func() {})
 	if prompt == "" {
 		prompt = "Enter data: "
 	}
 	fmt.Println(fmt.Sprintf("%s  [%s]:", prompt, "<RET> cancels"))
-	fmt.Print("=> ")
-	menuScanner.Scan()
-	input := strings.Trim(menuScanner.Text(), trimString)
+	var input string
+	for {
+		fmt.Print(inputPrompt)
+		editor := &lineEditor{prompt: inputPrompt, secret: secret}
+		if !secret {
+			editor.history = promptHistory(promptHistoryID(prompt))
+		}
+		input, _ = editor.read()
+		input = strings.Trim(input, trimString)
+		if secret {
+			break
+		}
+		recalled, isCommand := historyCommand(promptHistoryID(prompt), input)
+		if !isCommand {
+			input = recalled
+			addHistory(promptHistoryID(prompt), input)
+			break
+		}
+	}
 	if input == "" {
 		fmt.Println("<canceled>")
 	}
 	return input
 }
 
+//scanResult : a line read by menuScanner, see readLineTimeout
+type scanResult struct {
+	text string
+	ok   bool
+}
+
+//pendingScan : if a timed read gives up, its scan keeps waiting for the user
+//and the next read takes its result, menuScanner must not be scanned twice at once
+var pendingScan chan scanResult
+
+//readLine : internal use. All user input is read through here (or readLineTimeout).
+//ok is false if there is no more input.
+func readLine() (string, bool) {
+	if pendingScan != nil {
+		result := <-pendingScan
+		pendingScan = nil
+		return result.text, result.ok
+	}
+	ok := menuScanner.Scan()
+	return menuScanner.Text(), ok
+}
+
+//readLineTimeout : internal use. Same as readLine but gives up after timeout, in
+//which case timedOut is true. countdown returns the prompt showing the seconds
+//left, it is redrawn every second until the user starts typing, then countdown(0)
+//is shown instead. Input that is not a terminal is not seen until <RET>.
+func readLineTimeout(timeout time.Duration, countdown func(secondsLeft int) string) (text string, ok, timedOut bool) {
+	if pendingScan == nil && isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		if restore, err := makeRaw(os.Stdin); err == nil {
+			defer restore()
+			return editLineTimeout(timeout, countdown)
+		}
+	}
+
+	if pendingScan == nil {
+		pendingScan = make(chan scanResult, 1)
+		//a scan that gives up outlives this call, it must not see a later menuScanner
+		sc := menuScanner
+		go func(result chan<- scanResult) {
+			ok := sc.Scan()
+			result <- scanResult{sc.Text(), ok}
+		}(pendingScan)
+	}
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return "", true, true
+		}
+		fmt.Print("\r" + countdown(secondsLeft(left)))
+		select {
+		case result := <-pendingScan:
+			pendingScan = nil
+			return result.text, result.ok, false
+		case <-ticker.C:
+		case <-time.After(left):
+		}
+	}
+}
+
+//secondsLeft : internal use, left rounded up to whole seconds
+func secondsLeft(left time.Duration) int {
+	return int((left + time.Second - 1) / time.Second)
+}
+
 //descriptions of menuOptions settings.
 const (
 	idFuncRunnerInfo = `idFuncRunner: If true then when a Menu Entry runs 
@@ -1232,6 +2590,61 @@ will not pause after each msg. There is a lot of
 validation and error conditions checking but almost 
 all of them are not fatal and Menus will run in almost 
 any case.`
+	suggestKeysInfo = `suggestKeys: If true, input that is not a Menu Key
+is answered with a "Did you mean" list of the closest
+Keys (typos, swapped characters, wrong case).`
+	suggestRunInfo = `suggestRun: If true, and suggestKeys is true, and
+there is one best suggestion, the user is asked to
+confirm running it with a single 'y'.`
+	confirmBypassInfo = `confirmBypass: If true, Menu Entries that ask for
+confirmation before running (Menu.SetEntryConfirm)
+run straight away. For scripted or non-interactive
+use.`
+	historyFileInfo = `historyFile: If set, the input history of the menu
+and GetUserInput prompts is loaded from and saved to
+this file, so it is kept between runs. Empty string
+("") keeps the history in memory only.`
+	historyLimitInfo = `historyLimit: The number of inputs the history keeps,
+for all prompts together, oldest are dropped first.
+0, the default, turns the history off, so input
+starting with '!' is never taken as a recall.`
+	lineEditorInfo = `lineEditor: If true, and the program runs in a
+terminal, input at the menu prompt and GetUserInput
+prompts can be edited with the arrow keys, <Up> and
+<Down> recall the input history and <Tab> completes
+menu Keys. Otherwise input is read a line at a time.`
+	fullScreenInfo = `fullScreen: If true, and the program runs in a
+terminal, Menus take over the whole terminal window:
+the entries are chosen with the arrow keys and <RET>
+(or by typing a Key), and the output of the entry
+func()'s shows in a pane below the entries.`
+	rendererInfo = `renderer: Shows the Menus, unless they run full
+screen. nil is the classic layout. Built in are also
+BoxedRenderer, CompactRenderer and JSONRenderer, or
+implement the Renderer interface for your own. A
+Menu can have its own, see Menu.SetRenderer().`
+	themeInfo = `theme: The colours and styles of the breadcrumbs,
+Keys, hints, func brackets, warnings etc. Built in
+are ThemePlain, ThemeDefault, ThemeOcean and
+ThemeContrast, or see LoadTheme() for a theme file.`
+	colorModeInfo = `colorMode: ColorAuto shows the theme's colours only
+when the output is a terminal and the NO_COLOR
+environment variable is not set. ColorAlways and
+ColorNever override this.`
+	longHintsInfo = `longHints: What the classic layout does with hints
+too long for the line: LongHintWrap wraps them under
+their column, LongHintTruncate cuts them with an
+ellipsis, LongHintNone leaves them to the terminal.`
+	lineWidthInfo = `lineWidth: The width Menus are laid out for. 0
+follows the terminal's width, also when it is
+resized, and does not limit the width otherwise.`
+	outputModeInfo = `outputMode: Where the output of the entry func()'s
+goes. OutputDirect prints it as the func() runs.
+OutputPager keeps it until the func() returns and
+shows it a page at a time, with search, when it does
+not fit the terminal; OutputPagerEnv does so with the
+$PAGER program if it is set. An entry can have its
+own, see Menu.SetEntryOutput().`
 	funcBracketTopInfo = `funcBracketTop: func Brackets are strings printed before and 
 after a Menu\'s func() to make the output easier to differentiate
 from the Menu output. This string will be printed before the 
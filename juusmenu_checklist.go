@@ -0,0 +1,237 @@
+package juusmenu
+
+//Checklist menus: typing a Key marks or unmarks an item instead of running
+//its func(), and a confirm key exits the Menu with the marked items.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	checkAllKey           = "*"
+	checkNoneKey          = "-"
+	checkInvertKey        = "~"
+	checklistFooterFormat = "'%s' all  '%s' none  '%s' invert  '%s' %s  (%d selected)"
+)
+
+//checklistSettings : settings and result of a checklist Menu
+type checklistSettings struct {
+	confirmKey, confirmHint string
+	//min, max : number of items that must be marked to confirm, max 0 is no limit
+	min, max  int
+	confirmed bool
+}
+
+//SetChecklist : turns the Menu into a multi-select checklist. Typing an entry's Key
+//marks or unmarks it, '*' marks all, '-' none and '~' inverts the marks. Typing
+//confirmKey exits the Menu with the marks kept, the Break Item cancels, auto keys
+//skip these commands. At least min and at most max (0 is no limit) items must be
+//marked to confirm. Use StartChecklist() to run it and get the selection.
+func (menu *Menu) SetChecklist(confirmKey, confirmHint string, min, max int) error {
+	const methodName = "SetChecklist method: "
+	var errmsg string
+	valueClean(&confirmKey, &emptyString, vIgnore, func() {})
+	valueClean(&confirmHint, &emptyString, vIgnore, func() {})
+	switch {
+	case confirmKey == "":
+		errmsg = warn + fmt.Sprintf(methodName+"Menu '%s', empty confirm key sent in.", menu.Title)
+	case min < 0 || (max > 0 && min > max):
+		errmsg = warn + fmt.Sprintf(methodName+"Menu '%s', selection limits min %d, max %d are not possible.", menu.Title, min, max)
+	}
+	if errmsg != "" {
+		alertUser(&errmsg)
+		return errors.New(errmsg + " Checklist not set.")
+	}
+	if confirmHint == "" {
+		confirmHint = "confirm"
+	}
+	menu.checklist = &checklistSettings{
+		confirmKey:  confirmKey,
+		confirmHint: confirmHint,
+		min:         min,
+		max:         max,
+	}
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//AddChecklistItem : Adds an item to a checklist Menu carrying value, which is
+//returned by CheckedValues() when the item is marked. Items added with
+//AddMenuEntry carry their Key as value.
+func (menu *Menu) AddChecklistItem(key, hint string, value interface{}) error {
+	if err := menu.AddMenuEntry(key, hint, func() {}); err != nil {
+		return err
+	}
+	menu.entries[strings.Trim(key, trimString)].checkValue = value
+	return nil
+}
+
+//SetChecked : marks or unmarks a checklist item, for pre-selecting items.
+func (menu *Menu) SetChecked(key string, checked bool) error {
+	valueClean(&key, &emptyString, vIgnore, func() {})
+	entry, ok := menu.entries[key]
+	if !ok || key == breakIndicator {
+		errmsg := warn + fmt.Sprintf("SetChecked method: Menu '%s', item key '%s' does not exist.", menu.Title, key)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	entry.checked = checked
+	return nil
+}
+
+//StartChecklist : Starts a checklist Menu and returns the marked items' Keys (IDs for
+//auto keyed menus) in display order. If the user cancels, or the menu system is
+//killed, the result is nil and the marks are as they were before the start.
+func (menu *Menu) StartChecklist() ([]string, error) {
+	if menu.checklist == nil {
+		errmsg := warn + fmt.Sprintf("StartChecklist method: Menu '%s' is not a checklist, use SetChecklist().", menu.Title)
+		alertUser(&errmsg)
+		return nil, errors.New(errmsg)
+	}
+
+	before := make(map[string]bool, len(menu.entries))
+	for id, e := range menu.entries {
+		before[id] = e.checked
+	}
+	menu.checklist.confirmed = false
+	if err := menu.Start(); err != nil {
+		return nil, err
+	}
+	if !menu.checklist.confirmed {
+		for id, e := range menu.entries {
+			e.checked = before[id]
+		}
+		return nil, nil
+	}
+	return menu.Checked(), nil
+}
+
+//Checked : returns the marked items' Keys (IDs for auto keyed menus) in display order
+func (menu *Menu) Checked() []string {
+	var result []string
+	for _, id := range menu.sortKeys {
+		if menu.entries[id].checked && id != breakIndicator {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+//CheckedValues : returns the values of the marked items in display order,
+//see AddChecklistItem()
+func (menu *Menu) CheckedValues() []interface{} {
+	var result []interface{}
+	for _, id := range menu.Checked() {
+		if v := menu.entries[id].checkValue; v != nil {
+			result = append(result, v)
+			continue
+		}
+		result = append(result, id)
+	}
+	return result
+}
+
+//checklistCommand : internal use, handles the checklist commands typed at the menu
+//prompt. handled is true if input was a command, done if the Menu should exit.
+func (menu *Menu) checklistCommand(input string) (handled, done bool) {
+	items := menu.checkableItems()
+	mark := func(marked func(*menuEntry) bool) {
+		count := 0
+		for _, e := range items {
+			if marked(e) {
+				count++
+			}
+		}
+		if menu.checklist.max > 0 && count > menu.checklist.max {
+			fmt.Printf("???? At most %d may be selected.\n", menu.checklist.max)
+			WaitForInput(&emptyString)
+			return
+		}
+		for _, e := range items {
+			e.checked = marked(e)
+		}
+	}
+
+	switch input {
+	case checkAllKey:
+		mark(func(*menuEntry) bool { return true })
+	case checkNoneKey:
+		mark(func(*menuEntry) bool { return false })
+	case checkInvertKey:
+		mark(func(e *menuEntry) bool { return !e.checked })
+	case menu.checklist.confirmKey:
+		if n := len(menu.Checked()); n < menu.checklist.min {
+			fmt.Printf("???? At least %d must be selected, %d are.\n", menu.checklist.min, n)
+			WaitForInput(&emptyString)
+			return true, false
+		}
+		menu.checklist.confirmed = true
+		return true, true
+	default:
+		return false, false
+	}
+	return true, false
+}
+
+//toggleChecked : internal use, marks or unmarks a checklist item respecting the max limit
+func (menu *Menu) toggleChecked(entry *menuEntry) {
+	if !entry.checked && menu.checklist.max > 0 && len(menu.Checked()) >= menu.checklist.max {
+		fmt.Printf("???? At most %d may be selected, unmark one first.\n", menu.checklist.max)
+		WaitForInput(&emptyString)
+		return
+	}
+	entry.checked = !entry.checked
+}
+
+//checkableItems : internal use, the checklist items the user can currently see and mark
+func (menu *Menu) checkableItems() []*menuEntry {
+	var result []*menuEntry
+	for _, id := range menu.sortKeys {
+		if e := menu.entries[id]; id != breakIndicator && !e.hidden && !e.disabled {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+//checklistFooter : internal use, the line of checklist commands shown under the items
+func (menu *Menu) checklistFooter() string {
+	return fmt.Sprintf(checklistFooterFormat, checkAllKey, checkNoneKey, checkInvertKey,
+		menu.checklist.confirmKey, menu.checklist.confirmHint, len(menu.Checked()))
+}
+
+//validateChecklist : internal use, doValidate report on checklist Keys that can never be typed
+func (menu *Menu) validateChecklist() string {
+	const keysCommand = ">> Checklist Menu '%s' has a Key '%s' which conflicts with a checklist command, Entry ignored\n"
+	keys := map[string]bool{menu.quitValue: true}
+	//auto keys skip the checklist commands, their IDs don't matter
+	if menu.autoKeys == AutoKeyNone {
+		for id, entry := range menu.entries {
+			if id != breakIndicator {
+				keys[entry.value] = true
+			}
+		}
+	}
+	report := ""
+	for _, cmd := range []string{checkAllKey, checkNoneKey, checkInvertKey, menu.checklist.confirmKey} {
+		if keys[cmd] {
+			report = report + fmt.Sprintf(keysCommand, menu.Title, cmd)
+		}
+	}
+	return report
+}
+
+//isChecklistKey : internal use, true if key is taken by a checklist command
+func (menu *Menu) isChecklistKey(key string) bool {
+	return key == checkAllKey || key == checkNoneKey || key == checkInvertKey || key == menu.checklist.confirmKey
+}
+
+//checkMark : internal use, the mark displayed in front of a checklist item
+func checkMark(checked bool) string {
+	if checked {
+		return toggleOn
+	}
+	return toggleOff
+}
@@ -0,0 +1,236 @@
+package juusmenu
+
+//Columns: when a Menu's entries do not fit the terminal's height the classic
+//layout shows them in columns, filled top to bottom like ls does, with the Break
+//Item on a line of its own below them. Each column aligns its own Keys and hints,
+//hints too long for their column are truncated. Menu.SetColumns() sets the number
+//of columns of a Menu.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	//columnGap : the space between columns
+	columnGap = "   "
+	//keySeparator : between a Key and its hint, as the classic layout aligns them
+	keySeparator = " :  "
+)
+
+//SetColumns : Sets the number of columns the classic layout shows the Menu's
+//entries in. 0, the default, uses more than one only when the entries do not
+//fit the terminal's height, 1 always shows one column.
+func (menu *Menu) SetColumns(columns int) error {
+	if columns < 0 {
+		errmsg := warn + fmt.Sprintf("SetColumns(): Menu '%s' can not have %d columns, number of columns not changed.", menu.Title, columns)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.columns = columns
+	return nil
+}
+
+//lineHeight : internal use, the number of lines of the terminal, 0 if unknown
+func lineHeight() int {
+	if _, rows, err := terminalSize(os.Stdout); err == nil {
+		return rows
+	}
+	return 0
+}
+
+//columnItem : internal use, a line of a column, a section header or an entry
+type columnItem struct {
+	header string
+	ev     EntryView
+}
+
+//column : internal use, the lines of a column and the widths they are aligned to
+type column struct {
+	items                           []columnItem
+	keyWidth, hintWidth, badgeWidth int
+}
+
+//width : internal use, the number of terminal columns the column takes
+func (col *column) width() int {
+	width := col.keyWidth + len(keySeparator) + col.hintWidth
+	if col.badgeWidth > 0 {
+		width = width + 1 + col.badgeWidth
+	}
+	return width
+}
+
+//text : internal use, the line of the column showing item, not padded to the column width
+func (col *column) text(item columnItem, view *MenuView) string {
+	theme := view.Theme
+	if item.header != "" {
+		return theme.Section.Paint(fitWidth(item.header, col.width()))
+	}
+	padKey := padRight
+	if view.AlignRight {
+		padKey = padLeft
+	}
+	hint := truncate(item.ev.listHint(), col.hintWidth)
+	line := theme.Key.Paint(padKey(item.ev.Key, col.keyWidth)) + keySeparator + theme.hintStyle(item.ev).Paint(hint)
+	if item.ev.Badge != "" {
+		line = line + strings.Repeat(" ", col.hintWidth-displayWidth(hint)+1) + fmt.Sprintf(badgeFormat, item.ev.Badge)
+	}
+	return line
+}
+
+//columnItems : internal use, the lines the entries take in one column, without the Break Item
+func (view *MenuView) columnItems() []columnItem {
+	var items []columnItem
+	for _, ev := range view.Entries {
+		if header := ev.sectionHeader(); header != "" {
+			items = append(items, columnItem{header: header})
+		}
+		items = append(items, columnItem{ev: ev})
+	}
+	return items
+}
+
+//splitColumns : internal use, fills at most n columns with items, top to bottom.
+//Fewer columns are returned if n of them would leave the last ones empty.
+func splitColumns(items []columnItem, n int) []*column {
+	rows := (len(items) + n - 1) / n
+	var cols []*column
+	for len(items) > 0 {
+		size := rows
+		if size > len(items) {
+			size = len(items)
+		}
+		col := &column{items: items[:size]}
+		for _, item := range col.items {
+			if item.header != "" {
+				continue
+			}
+			if w := displayWidth(item.ev.Key); w > col.keyWidth {
+				col.keyWidth = w
+			}
+			if w := displayWidth(item.ev.listHint()); w > col.hintWidth {
+				col.hintWidth = w
+			}
+			if w := displayWidth(fmt.Sprintf(badgeFormat, item.ev.Badge)); item.ev.Badge != "" && w > col.badgeWidth {
+				col.badgeWidth = w
+			}
+		}
+		cols = append(cols, col)
+		items = items[size:]
+	}
+	return cols
+}
+
+//fitColumns : internal use, narrows the widest hints of cols until the columns fit
+//in width, 0 is no limit. false if hints would be narrower than minHintWidth.
+func fitColumns(cols []*column, width int) bool {
+	if width <= 0 {
+		return true
+	}
+	left := width - len(columnGap)*(len(cols)-1)
+	for _, col := range cols {
+		left = left - (col.width() - col.hintWidth)
+	}
+	//hints narrower than an even share keep their width, the others share the rest
+	wide := cols
+	for len(wide) > 0 {
+		share := left / len(wide)
+		var wider []*column
+		for _, col := range wide {
+			if col.hintWidth <= share {
+				left = left - col.hintWidth
+				continue
+			}
+			wider = append(wider, col)
+		}
+		if len(wider) == len(wide) {
+			if share < 1 {
+				share = 1
+			}
+			for _, col := range wider {
+				col.hintWidth = share
+			}
+			return share >= minHintWidth
+		}
+		wide = wider
+	}
+	return true
+}
+
+//columnLayout : internal use, the entries in columns for the classic layout, nil
+//to show them in one column, which takes lines lines
+func (view *MenuView) columnLayout(lines int) []*column {
+	items := view.columnItems()
+	var cols []*column
+	switch {
+	case view.Columns == 1 || len(items) < 2:
+		return nil
+	case view.Columns > 1:
+		cols = splitColumns(items, view.Columns)
+		fitColumns(cols, view.Width)
+	case view.Height <= 0 || view.chromeLines()+lines <= view.Height:
+		return nil
+	default:
+		//the fewest columns that fit the height, or the most that fit the width
+		rows := view.Height - view.chromeLines() - 2
+		for n := 2; n <= len(items); n++ {
+			split := splitColumns(items, n)
+			if len(split) < n {
+				continue
+			}
+			if !fitColumns(split, view.Width) {
+				break
+			}
+			cols = split
+			if len(cols[0].items) <= rows {
+				break
+			}
+		}
+	}
+	if len(cols) < 2 {
+		return nil
+	}
+	return cols
+}
+
+//chromeLines : internal use, the lines the classic layout shows besides the entries
+func (view *MenuView) chromeLines() int {
+	//blank line, breadcrumbs, rule, footer and prompt
+	return 3 + len(view.footerLines()) + 1
+}
+
+//renderColumns : internal use, the classic layout of the entries in cols, the
+//Break Item separated from them by a blank line
+func (view *MenuView) renderColumns(w io.Writer, cols []*column) {
+	padKey := padRight
+	if view.AlignRight {
+		padKey = padLeft
+	}
+	quit := view.Theme.Key.Paint(padKey(view.Quit.Key, cols[0].keyWidth)) + keySeparator +
+		view.Theme.hintStyle(view.Quit).Paint(view.Quit.listHint())
+	if view.QuitFirst {
+		fmt.Fprintln(w, quit)
+		fmt.Fprintln(w, "")
+	}
+	for row := range cols[0].items {
+		var b strings.Builder
+		for c, col := range cols {
+			if row >= len(col.items) {
+				break
+			}
+			line := col.text(col.items[row], view)
+			if c+1 < len(cols) && row < len(cols[c+1].items) {
+				line = padRight(line, col.width()) + columnGap
+			}
+			b.WriteString(line)
+		}
+		fmt.Fprintln(w, b.String())
+	}
+	if !view.QuitFirst {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, quit)
+	}
+}
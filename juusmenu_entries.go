@@ -0,0 +1,240 @@
+package juusmenu
+
+//Stateful Menu Entries: toggles, cycles and counters. Each is bound to
+//a variable, shows the variable's current value in its menu line, and
+//selecting it changes the value. The menu is then redisplayed straight
+//away, there are no func brackets and no pause.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	toggleOn      = "[x] "
+	toggleOff     = "[ ] "
+	cycleFormat   = "%s: <%s>"
+	counterFormat = "%s: %d  (%d..%d)"
+)
+
+//addStateEntry : internal use, adds an entry whose doRun changes bound state
+func (menu *Menu) addStateEntry(key, hint string, change func(), decorate func(string) string) error {
+	if err := menu.AddMenuEntry(key, hint, change); err != nil {
+		return err
+	}
+	entry := menu.entries[strings.Trim(key, trimString)]
+	entry.isStateEntry = true
+	entry.decorate = decorate
+	return nil
+}
+
+//stateEntryError : internal use, reports a bad parameter to one of the Add<State> methods
+func (menu *Menu) stateEntryError(methodName, key, problem string) error {
+	errmsg := warn + fmt.Sprintf("%s method: Menu '%s', key '%s': %s. Entry not added.", methodName, menu.Title, key, problem)
+	alertUser(&errmsg)
+	return errors.New(errmsg)
+}
+
+//AddToggle : Adds an entry bound to val, shown as [x] or [ ] in front of the hint.
+//Selecting it flips val and then calls onChange (which may be nil) with the new value.
+func (menu *Menu) AddToggle(key, hint string, val *bool, onChange func(bool)) error {
+	if val == nil {
+		return menu.stateEntryError("AddToggle", key, "val was nil")
+	}
+	return menu.addStateEntry(key, hint,
+		func() {
+			*val = !*val
+			if onChange != nil {
+				onChange(*val)
+			}
+		},
+		func(h string) string {
+			if *val {
+				return toggleOn + h
+			}
+			return toggleOff + h
+		})
+}
+
+//AddCycle : Adds an entry bound to val, shown as "hint: <val>". Selecting it sets
+//val to the next of options, wrapping around, and then calls onChange (which may
+//be nil). If val is not one of options the first selection sets options[0].
+func (menu *Menu) AddCycle(key, hint string, val *string, options []string, onChange func(string)) error {
+	if val == nil {
+		return menu.stateEntryError("AddCycle", key, "val was nil")
+	}
+	if len(options) == 0 {
+		return menu.stateEntryError("AddCycle", key, "no options sent in")
+	}
+	opts := append([]string(nil), options...)
+	return menu.addStateEntry(key, hint,
+		func() {
+			next := 0
+			for i, o := range opts {
+				if o == *val {
+					next = (i + 1) % len(opts)
+					break
+				}
+			}
+			*val = opts[next]
+			if onChange != nil {
+				onChange(*val)
+			}
+		},
+		func(h string) string {
+			return fmt.Sprintf(cycleFormat, h, *val)
+		})
+}
+
+//AddCounter : Adds an entry bound to val, shown as "hint: val  (min..max)". Selecting it
+//asks the user for '+' or '-' to step val by step, or for a number to set it to.
+//val stays within min..max. onChange (which may be nil) is called when val changed.
+func (menu *Menu) AddCounter(key, hint string, val *int, min, max, step int, onChange func(int)) error {
+	if val == nil {
+		return menu.stateEntryError("AddCounter", key, "val was nil")
+	}
+	if min > max {
+		return menu.stateEntryError("AddCounter", key, fmt.Sprintf("min %d is larger than max %d", min, max))
+	}
+	if step < 1 {
+		step = 1
+	}
+	return menu.addStateEntry(key, hint,
+		func() {
+			input := GetUserInput(fmt.Sprintf("%s is %d. '+' or '-' steps by %d, or enter a number %d..%d", strings.Trim(hint, trimString), *val, step, min, max))
+			newVal := *val
+			switch input {
+			case "":
+				return
+			case "+":
+				newVal += step
+			case "-":
+				newVal -= step
+			default:
+				n, err := strconv.Atoi(input)
+				if err != nil {
+					fmt.Printf("'%s' is not a number, value not changed.\n", input)
+					return
+				}
+				newVal = n
+			}
+			if newVal < min || newVal > max {
+				fmt.Printf("%d is outside %d..%d, value not changed.\n", newVal, min, max)
+				return
+			}
+			if newVal != *val {
+				*val = newVal
+				if onChange != nil {
+					onChange(*val)
+				}
+			}
+		},
+		func(h string) string {
+			return fmt.Sprintf(counterFormat, h, *val, min, max)
+		})
+}
+
+const (
+	radioOn  = "(*) "
+	radioOff = "( ) "
+)
+
+//radioGroup : the variable shared by the entries of a radio group
+type radioGroup struct {
+	bind     *string
+	onChange func(string)
+}
+
+//AddRadio : Adds an entry to the radio group named group. All entries of a group are
+//bound to the same variable bind, and selecting one sets bind to its value, which
+//marks it (*) and clears the others. The menu stays open, unlike a SetChooseOne menu.
+//If bind does not hold the value of one of the group's entries when the Menu is
+//displayed, it is set to the value of the group's first entry in display order.
+func (menu *Menu) AddRadio(group, key, hint string, bind *string, value string) error {
+	valueClean(&group, &emptyString, vIgnore, func() {})
+	switch {
+	case group == "":
+		return menu.stateEntryError("AddRadio", key, "empty group name sent in")
+	case bind == nil:
+		return menu.stateEntryError("AddRadio", key, "bind was nil")
+	}
+	rg, ok := menu.radioGroups[group]
+	if !ok {
+		rg = &radioGroup{bind: bind}
+	} else if rg.bind != bind {
+		return menu.stateEntryError("AddRadio", key, fmt.Sprintf("radio group '%s' is bound to a different variable", group))
+	}
+
+	err := menu.addStateEntry(key, hint,
+		func() {
+			if *rg.bind == value {
+				return
+			}
+			*rg.bind = value
+			if rg.onChange != nil {
+				rg.onChange(value)
+			}
+		},
+		func(h string) string {
+			if *rg.bind == value {
+				return radioOn + h
+			}
+			return radioOff + h
+		})
+	if err != nil {
+		return err
+	}
+	//a new group only exists once its first entry was added
+	if !ok {
+		if menu.radioGroups == nil {
+			menu.radioGroups = make(map[string]*radioGroup)
+		}
+		menu.radioGroups[group] = rg
+	}
+	entry := menu.entries[strings.Trim(key, trimString)]
+	entry.radioGroup, entry.radioValue = group, value
+	return nil
+}
+
+//SetRadioOnChange : onChange is called with the new value whenever the user
+//selects a different entry of the radio group. nil removes it.
+func (menu *Menu) SetRadioOnChange(group string, onChange func(string)) error {
+	valueClean(&group, &emptyString, vIgnore, func() {})
+	rg, ok := menu.radioGroups[group]
+	if !ok {
+		errmsg := warn + fmt.Sprintf("SetRadioOnChange method: Menu '%s', radio group '%s' does not exist, use AddRadio().", menu.Title, group)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	rg.onChange = onChange
+	return nil
+}
+
+//settleRadioGroups : internal use, called at display. Makes sure exactly one entry
+//of each radio group is marked by falling back to the group's first entry.
+func (menu *Menu) settleRadioGroups() {
+	if len(menu.radioGroups) == 0 {
+		return
+	}
+	first := make(map[string]string)
+	matched := make(map[string]bool)
+	for _, id := range menu.sortKeys {
+		e := menu.entries[id]
+		if e.radioGroup == "" {
+			continue
+		}
+		if _, ok := first[e.radioGroup]; !ok {
+			first[e.radioGroup] = e.radioValue
+		}
+		if *menu.radioGroups[e.radioGroup].bind == e.radioValue {
+			matched[e.radioGroup] = true
+		}
+	}
+	for group, value := range first {
+		if !matched[group] {
+			*menu.radioGroups[group].bind = value
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package juusmenu
+
+//Forms: several fields filled in through a menu and then submitted together.
+//A Form is built on a Menu, each field is an entry showing its current value,
+//selecting it prompts for a new value. The submit entry runs a callback with all
+//values, but only once every field validates. Cancel discards the changes.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//FieldKind : the type of value a Form field accepts
+type FieldKind int
+
+const (
+	//FieldText : any text
+	FieldText FieldKind = iota
+	//FieldInt : a whole number
+	FieldInt
+	//FieldFloat : a number
+	FieldFloat
+	//FieldBool : yes or no, stored as "true" or "false"
+	FieldBool
+)
+
+func (fk FieldKind) String() string {
+	return enumString("FieldKind", int(fk), "text", "whole number", "number", "yes/no")
+}
+
+const (
+	formFieldFormat    = "%s: %s"
+	formRequiredMark   = " *"
+	formEmptyValue     = "<empty>"
+	formClearInput     = "-"
+	formRequiredLegend = "(fields marked * are required)"
+)
+
+//"constants", but there is a need to pass &addresses
+var (
+	defFormSubmitKey  = "s"
+	defFormSubmitHint = "Submit"
+	defFormCancelKey  = "c"
+	defFormCancelHint = "Cancel, discard changes"
+)
+
+//formField : one field of a Form
+type formField struct {
+	name, label string
+	kind        FieldKind
+	required    bool
+	value       string
+	validate    func(string) error
+}
+
+//FormValues : the values of a Form's fields by field name, as the user typed them.
+//FieldBool values are "true" or "false". Use the typed getters to convert.
+type FormValues map[string]string
+
+//Int : returns the named value as an int
+func (fv FormValues) Int(name string) (int, error) {
+	return strconv.Atoi(fv[name])
+}
+
+//Float : returns the named value as a float64
+func (fv FormValues) Float(name string) (float64, error) {
+	return strconv.ParseFloat(fv[name], 64)
+}
+
+//Bool : returns the named value as a bool
+func (fv FormValues) Bool(name string) (bool, error) {
+	return strconv.ParseBool(fv[name])
+}
+
+//Form : a Menu of fields that are filled in and then submitted together
+type Form struct {
+	menu      *Menu
+	fields    []*formField
+	onSubmit  func(FormValues)
+	submitKey string
+	submitted bool
+}
+
+//NewForm : Returns a new Form. Fields are numbered 1..N in the order they are added.
+//The submit entry defaults to 's', the cancel (Break) entry to 'c', see SetSubmit
+//and SetCancel. onSubmit may be nil and set later with SetSubmit.
+func NewForm(title string, onSubmit func(FormValues)) *Form {
+	form := &Form{
+		menu:     NewMenu(title),
+		onSubmit: onSubmit,
+	}
+	//a Form is run with Start(), never by StartMenuSystem()
+	form.menu.isMainMenu = false
+	form.menu.SetSortOrder(OrderInsertion)
+	form.menu.AddSection(formSectionID, formRequiredLegend)
+	form.SetCancel(defFormCancelKey, defFormCancelHint)
+	form.SetSubmit(defFormSubmitKey, defFormSubmitHint, onSubmit)
+	return form
+}
+
+//formSectionID : the submit entry is kept in its own section, below the fields
+const formSectionID = "form.submit"
+
+//Menu : returns the Form's Menu, to change its appearance. Use the Form's methods
+//to change fields, submit and cancel. Do not AddSubMenu() it: only Start() discards
+//the changes on cancel and tracks the submit, run the Form from a parent Menu with
+//an entry whose func() calls form.Start().
+func (form *Form) Menu() *Menu {
+	return form.menu
+}
+
+//AddField : Adds a field. name identifies the field in FormValues, label is shown
+//to the user. required fields must have a value to submit.
+func (form *Form) AddField(name, label string, kind FieldKind, required bool) error {
+	valueClean(&name, &emptyString, vIgnore, func() {})
+	valueClean(&label, &name, vIgnore, func() {})
+	if name == "" || form.field(name) != nil {
+		errmsg := warn + fmt.Sprintf("AddField method: Form '%s', field name '%s' is empty or already used. Field not added.", form.menu.Title, name)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	field := &formField{name: name, label: label, kind: kind, required: required}
+	key := strconv.Itoa(len(form.fields) + 1)
+	if err := form.menu.AddMenuEntry(key, label, func() { form.editField(field) }); err != nil {
+		return err
+	}
+	entry := form.menu.entries[key]
+	entry.isStateEntry = true
+	entry.decorate = func(string) string {
+		label := field.label
+		if field.required {
+			label = label + formRequiredMark
+		}
+		if field.value == "" {
+			return fmt.Sprintf(formFieldFormat, label, formEmptyValue)
+		}
+		return fmt.Sprintf(formFieldFormat, label, field.value)
+	}
+	form.fields = append(form.fields, field)
+	return nil
+}
+
+//SetFieldValidator : validate is run on a new value of the field after its type is
+//checked, a non nil error rejects the value and is shown to the user.
+func (form *Form) SetFieldValidator(name string, validate func(string) error) error {
+	field := form.field(name)
+	if field == nil {
+		return form.fieldError("SetFieldValidator", name)
+	}
+	field.validate = validate
+	return nil
+}
+
+//SetFieldValue : Sets the value of a field, for defaults or editing existing records.
+//The value is not validated until submit.
+func (form *Form) SetFieldValue(name, value string) error {
+	field := form.field(name)
+	if field == nil {
+		return form.fieldError("SetFieldValue", name)
+	}
+	field.value = strings.Trim(value, trimString)
+	return nil
+}
+
+//SetSubmit : Sets the submit entry's Key and hint and the callback it runs with all
+//values. Submitting is refused while any field fails validation.
+func (form *Form) SetSubmit(key, hint string, onSubmit func(FormValues)) error {
+	valueClean(&key, &defFormSubmitKey, vIgnore, func() {})
+	valueClean(&hint, &defFormSubmitHint, vIgnore, func() {})
+	if _, err := strconv.Atoi(key); err == nil || key == form.menu.quitValue {
+		errmsg := warn + fmt.Sprintf("SetSubmit method: Form '%s', submit key '%s' conflicts with a field or the cancel key. Submit not changed.", form.menu.Title, key)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	if form.submitKey != "" {
+		form.menu.RemoveMenuEntry(form.submitKey)
+	}
+	form.submitKey = key
+	form.onSubmit = onSubmit
+	return form.menu.AddSectionEntry(formSectionID, key, hint, form.submit)
+}
+
+//SetCancel : Sets the cancel entry's Key and hint, it is the Form Menu's Break Item.
+func (form *Form) SetCancel(key, hint string) error {
+	valueClean(&key, &defFormCancelKey, vIgnore, func() {})
+	valueClean(&hint, &defFormCancelHint, vIgnore, func() {})
+	if _, err := strconv.Atoi(key); err == nil || key == form.submitKey {
+		errmsg := warn + fmt.Sprintf("SetCancel method: Form '%s', cancel key '%s' conflicts with a field or the submit key. Cancel not changed.", form.menu.Title, key)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	return form.menu.SetMenuBreakItem(key, hint, func() {})
+}
+
+//Start : Runs the Form until it is submitted or canceled. submitted is false if the
+//user canceled, in which case the fields are reset to their values before the start.
+func (form *Form) Start() (submitted bool, err error) {
+	before := form.Values()
+	form.submitted = false
+	if err = form.menu.Start(); err != nil {
+		return false, err
+	}
+	if !form.submitted {
+		for _, f := range form.fields {
+			f.value = before[f.name]
+		}
+	}
+	return form.submitted, nil
+}
+
+//Values : returns the current values of all fields
+func (form *Form) Values() FormValues {
+	result := make(FormValues, len(form.fields))
+	for _, f := range form.fields {
+		result[f.name] = f.value
+	}
+	return result
+}
+
+//Validate : checks every field, returns one error listing all problems, or nil
+func (form *Form) Validate() error {
+	var problems []string
+	for _, f := range form.fields {
+		if f.value == "" {
+			if f.required {
+				problems = append(problems, fmt.Sprintf("'%s' is required", f.label))
+			}
+			continue
+		}
+		if _, err := f.check(f.value); err != nil {
+			problems = append(problems, fmt.Sprintf("'%s': %s", f.label, err))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+//submit : internal use, the submit entry's func()
+func (form *Form) submit() {
+	if err := form.Validate(); err != nil {
+		fmt.Println("Can not submit yet:\n" + err.Error())
+		return
+	}
+	//the values are handed on as check() normalized them, "yes" as "true"
+	for _, f := range form.fields {
+		if f.value != "" {
+			f.value, _ = f.check(f.value)
+		}
+	}
+	if form.onSubmit != nil {
+		form.onSubmit(form.Values())
+	}
+	form.submitted = true
+	form.menu.Close()
+}
+
+//editField : internal use, a field entry's func(), prompts for a new value
+func (form *Form) editField(field *formField) {
+	prompt := fmt.Sprintf("%s (%s)", field.label, field.kind)
+	if field.value != "" {
+		prompt = prompt + fmt.Sprintf(", currently '%s', '%s' clears it", field.value, formClearInput)
+	}
+	input := GetUserInput(prompt)
+	switch input {
+	case "":
+		return
+	case formClearInput:
+		field.value = ""
+		return
+	}
+	value, err := field.check(input)
+	if err != nil {
+		msg := fmt.Sprintf("'%s' not accepted for '%s': %s", input, field.label, err)
+		WaitForInput(&msg)
+		return
+	}
+	field.value = value
+}
+
+//check : internal use, validates a value for the field, returning it normalized
+func (field *formField) check(value string) (string, error) {
+	switch field.kind {
+	case FieldInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", errors.New("not a whole number")
+		}
+	case FieldFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", errors.New("not a number")
+		}
+	case FieldBool:
+		switch strings.ToLower(value) {
+		case "y", "yes", "true", "1":
+			value = "true"
+		case "n", "no", "false", "0":
+			value = "false"
+		default:
+			return "", errors.New("answer yes or no")
+		}
+	}
+	if field.validate != nil {
+		if err := field.validate(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+//field : internal use, returns the named field or nil
+func (form *Form) field(name string) *formField {
+	name = strings.Trim(name, trimString)
+	for _, f := range form.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+//fieldError : internal use, reports a field name that does not exist
+func (form *Form) fieldError(methodName, name string) error {
+	errmsg := warn + fmt.Sprintf("%s method: Form '%s', field '%s' does not exist.", methodName, form.menu.Title, name)
+	alertUser(&errmsg)
+	return errors.New(errmsg)
+}
@@ -0,0 +1,168 @@
+package juusmenu
+
+//Input history: everything typed at menu prompts and GetUserInput prompts is
+//kept per prompt. At any of those prompts '!h' lists the prompt's history,
+//'!!' recalls its last input and '!n' its n'th. The history is off until it is
+//given a limit with MenuOptions.SetHistoryLimit(), or SetHistoryFile() to keep
+//it in a file between runs.
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	historyListCmd   = "!h"
+	historyLastCmd   = "!!"
+	historyCmdPrefix = "!"
+	historyRecalled  = "(recalled '%s')\n"
+	historyLine      = "%5d  %s\n"
+)
+
+//historyEntry : one input, id says at which prompt it was typed
+type historyEntry struct {
+	id, text string
+}
+
+//inputHistory : all inputs kept, oldest first
+var inputHistory []historyEntry
+
+//SetHistoryFile : Keep the input history in file between runs. The file is read
+//right away, and re-written after every input, keeping at most limit inputs.
+//An empty path keeps the history in memory only. limit 0 turns the history off.
+func (mo *menuOptions) SetHistoryFile(path string, limit int) error {
+	valueClean(&path, &emptyString, vIgnore, func() {})
+	if limit < 0 {
+		limit = 0
+	}
+	mo.historyFile = path
+	mo.historyLimit = limit
+	if path == "" {
+		trimHistory()
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		errmsg := warn + fmt.Sprintf("SetHistoryFile method: can not read history file '%s': %s", path, err)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	loaded := make([]historyEntry, 0, limit)
+	for _, line := range strings.Split(string(data), "\n") {
+		if parts := strings.SplitN(line, "\t", 2); len(parts) == 2 && parts[1] != "" {
+			loaded = append(loaded, historyEntry{id: parts[0], text: parts[1]})
+		}
+	}
+	//inputs typed before the file was set come after the ones from the file
+	inputHistory = append(loaded, inputHistory...)
+	trimHistory()
+	return nil
+}
+
+//SetHistoryLimit : the number of inputs the history keeps, 0, the default, turns it off
+func (mo *menuOptions) SetHistoryLimit(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+	mo.historyLimit = limit
+	trimHistory()
+}
+
+//historyID : internal use, the history identity of a Menu's prompt
+func (menu *Menu) historyID() string {
+	return "menu:" + menu.Title
+}
+
+//promptHistoryID : internal use, the history identity of a GetUserInput prompt
+func promptHistoryID(prompt string) string {
+	return "prompt:" + prompt
+}
+
+//promptHistory : internal use, the inputs typed at prompt id, oldest first
+func promptHistory(id string) []string {
+	var result []string
+	for _, h := range inputHistory {
+		if h.id == id {
+			result = append(result, h.text)
+		}
+	}
+	return result
+}
+
+//historyCommand : internal use. If input is a history command typed at prompt id it is
+//handled: '!h' lists the history and isCommand is true, the caller prompts again;
+//'!!' and '!n' are replaced by the recalled input. Other input is returned as is.
+func historyCommand(id, input string) (recalled string, isCommand bool) {
+	if MenuOptions.historyLimit == 0 || !strings.HasPrefix(input, historyCmdPrefix) {
+		return input, false
+	}
+	list := promptHistory(id)
+	switch {
+	case input == historyListCmd:
+		if len(list) == 0 {
+			fmt.Println("<no history>")
+		}
+		for i, text := range list {
+			fmt.Printf(historyLine, i+1, text)
+		}
+		return "", true
+	case input == historyLastCmd:
+		if len(list) == 0 {
+			fmt.Println("<no history>")
+			return "", true
+		}
+		recalled = list[len(list)-1]
+	default:
+		n, err := strconv.Atoi(strings.TrimPrefix(input, historyCmdPrefix))
+		if err != nil {
+			//not a history command after all
+			return input, false
+		}
+		if n < 1 || n > len(list) {
+			fmt.Printf("<no history entry %d>\n", n)
+			return "", true
+		}
+		recalled = list[n-1]
+	}
+	fmt.Printf(historyRecalled, recalled)
+	return recalled, false
+}
+
+//addHistory : internal use, keeps input typed at prompt id, blank input is not kept
+func addHistory(id, input string) {
+	if MenuOptions.historyLimit == 0 || input == "" {
+		return
+	}
+	inputHistory = append(inputHistory, historyEntry{id: id, text: input})
+	trimHistory()
+	saveHistory()
+}
+
+//trimHistory : internal use, drops the oldest inputs over the limit
+func trimHistory() {
+	if over := len(inputHistory) - MenuOptions.historyLimit; over > 0 {
+		inputHistory = append([]historyEntry(nil), inputHistory[over:]...)
+	}
+}
+
+//saveHistory : internal use, writes the history file, if there is one
+func saveHistory() {
+	if MenuOptions.historyFile == "" {
+		return
+	}
+	var sb strings.Builder
+	for _, h := range inputHistory {
+		sb.WriteString(strings.Replace(h.id, "\t", " ", -1) + "\t" + h.text + "\n")
+	}
+	if err := ioutil.WriteFile(MenuOptions.historyFile, []byte(sb.String()), 0600); err != nil {
+		errmsg := warn + fmt.Sprintf("History: can not write history file '%s', history is kept in memory only: %s", MenuOptions.historyFile, err)
+		//don't complain again after every input
+		MenuOptions.historyFile = ""
+		alertUser(&errmsg)
+	}
+}
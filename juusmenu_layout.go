@@ -0,0 +1,321 @@
+package juusmenu
+
+//Layout: text is measured in terminal columns, not bytes or runes, so Keys and
+//hints with wide (CJK, emoji) or combining characters line up. Hints too long
+//for the line are wrapped under their column or truncated, see
+//MenuOptions.SetLongHints(), for the terminal's width or a fixed one, see
+//MenuOptions.SetLineWidth(). When the terminal is resized the Menu waiting for
+//input is shown again for the new size.
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//LongHintMode : what the classic layout does with hints too long for the line
+type LongHintMode int
+
+const (
+	//LongHintWrap : Default. Long hints wrap under their column, indented
+	LongHintWrap LongHintMode = iota
+	//LongHintTruncate : Long hints are cut, ending in an ellipsis
+	LongHintTruncate
+	//LongHintNone : Long hints are left to the terminal
+	LongHintNone
+)
+
+func (lh LongHintMode) String() string {
+	return enumString("LongHintMode", int(lh), "LongHintWrap", "LongHintTruncate", "LongHintNone")
+}
+
+const (
+	//hangIndent : continuation lines of wrapped hints are indented this much more
+	hangIndent = "  "
+	//minHintWidth : hints are never wrapped narrower than this
+	minHintWidth = 12
+	ellipsis     = "…"
+)
+
+//SetLongHints : Sets what the classic layout does with hints too long for the line.
+func (mo *menuOptions) SetLongHints(mode LongHintMode) {
+	mo.longHints = mode
+}
+
+//SetLineWidth : Sets the width Menus are laid out for. 0, the default, follows the
+//terminal, or does not limit the width when the output is not a terminal.
+func (mo *menuOptions) SetLineWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	mo.lineWidth = width
+}
+
+//lineWidth : internal use, the width to lay out for, 0 for no limit
+func lineWidth() int {
+	if MenuOptions.lineWidth > 0 {
+		return MenuOptions.lineWidth
+	}
+	if cols, _, err := terminalSize(os.Stdout); err == nil {
+		return cols
+	}
+	return 0
+}
+
+//displayWidth : internal use, the number of terminal columns s takes. ANSI colour
+//sequences take none.
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+//escapeLength : internal use, the length of the ANSI sequence s starts with, or 0
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+//wideRanges : East Asian Wide and Fullwidth characters and emoji, two columns wide
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x18AFF},
+	{0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A}, {0x1F200, 0x1F251}, {0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF},
+	{0x1F7E0, 0x1F7EB}, {0x1F90C, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x3FFFD},
+}
+
+//runeWidth : internal use, the number of terminal columns r takes
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r < 32 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x1100:
+		if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			return 0
+		}
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) || (r >= 0x1160 && r <= 0x11FF):
+		//combining marks, zero width joiner, variation selectors, Hangul jamo
+		return 0
+	}
+	for _, wr := range wideRanges {
+		if r < wr.lo {
+			break
+		}
+		if r <= wr.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+//padRight : internal use, pads s with spaces to width columns
+func padRight(s string, width int) string {
+	if n := displayWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+//padLeft : internal use, pads s with spaces on the left to width columns
+func padLeft(s string, width int) string {
+	if n := displayWidth(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+//fitWidth : internal use, cuts s to at most width columns. The result is always
+//a prefix of s, also if s is not valid UTF-8.
+func fitWidth(s string, width int) string {
+	used := 0
+	for i, r := range s {
+		if used+runeWidth(r) > width {
+			return s[:i]
+		}
+		used += runeWidth(r)
+	}
+	return s
+}
+
+//truncate : internal use, cuts s to width columns, ending in an ellipsis if cut
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	return fitWidth(s, width-1) + ellipsis
+}
+
+//wrapWords : internal use, breaks s into lines of at most first columns, then at most
+//rest columns, at spaces if possible
+func wrapWords(s string, first, rest int) []string {
+	var lines []string
+	line, width := "", first
+	flush := func() {
+		lines = append(lines, line)
+		line, width = "", rest
+	}
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+		case displayWidth(line)+1+displayWidth(word) <= width:
+			line = line + " "
+		default:
+			flush()
+		}
+		for line == "" && displayWidth(word) > width {
+			//a word longer than the line is broken
+			part := fitWidth(word, width)
+			if part == "" {
+				_, size := utf8.DecodeRuneInString(word)
+				part = word[:size]
+			}
+			line, word = part, word[len(part):]
+			flush()
+		}
+		line = line + word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+//fitHint : internal use, the lines of a hint for a column width columns wide, 0 is unlimited
+func fitHint(hint string, width int, mode LongHintMode) []string {
+	if width <= 0 || mode == LongHintNone || displayWidth(hint) <= width {
+		return []string{hint}
+	}
+	if mode == LongHintTruncate {
+		return []string{truncate(hint, width)}
+	}
+	lines := wrapWords(hint, width, width-len(hangIndent))
+	for i := 1; i < len(lines); i++ {
+		lines[i] = hangIndent + lines[i]
+	}
+	return lines
+}
+
+//alignCells : internal use, lays out rows of cells in columns, like text/tabwriter
+//but measuring display width. All cells but the last of a row are padded to the
+//width of their column plus padding. A column is aligned over consecutive rows
+//that have a padded cell in it, a row of one cell interrupts all columns.
+func alignCells(rows [][]string, padding int, alignRight bool) []string {
+	widths := make([][]int, len(rows))
+	for i, row := range rows {
+		widths[i] = make([]int, len(row))
+	}
+	var format func(first, last, col int)
+	format = func(first, last, col int) {
+		for i := first; i < last; i++ {
+			if col >= len(rows[i])-1 {
+				continue
+			}
+			end, width := i, 0
+			for ; end < last && col < len(rows[end])-1; end++ {
+				if w := displayWidth(rows[end][col]) + padding; w > width {
+					width = w
+				}
+			}
+			for j := i; j < end; j++ {
+				widths[j][col] = width
+			}
+			format(i, end, col+1)
+			i = end - 1
+		}
+	}
+	format(0, len(rows), 0)
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		var b strings.Builder
+		for j, cell := range row {
+			switch {
+			case j == len(row)-1:
+				b.WriteString(cell)
+			case alignRight:
+				b.WriteString(padLeft(cell, widths[i][j]))
+			default:
+				b.WriteString(padRight(cell, widths[i][j]))
+			}
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+//waiting : what is redrawn when the terminal is resized while waiting for input.
+//Input loops reading a key at a time hold the lock while they handle a key, so
+//a redraw does not mix with their output.
+var waiting struct {
+	sync.Mutex
+	redraw func()
+	once   sync.Once
+	//captured : os.Stdout goes to a buffer, see captureOutput, nothing is redrawn
+	captured bool
+}
+
+//redisplay : internal use, returns the func() that displays the Menu again when
+//the width or height it was laid out for changed
+func (menu *Menu) redisplay() func() {
+	width, height := lineWidth(), lineHeight()
+	return func() {
+		if lineWidth() == width && lineHeight() == height {
+			return
+		}
+		width, height = lineWidth(), lineHeight()
+		fmt.Print("\r\x1b[K")
+		menu.displayMenu()
+	}
+}
+
+//whileWaiting : internal use, redraw is called when the terminal is resized, until
+//the returned func() is called
+func whileWaiting(redraw func()) (done func()) {
+	waiting.once.Do(func() {
+		watchResize(func() {
+			waiting.Lock()
+			defer waiting.Unlock()
+			if waiting.redraw != nil && !waiting.captured {
+				waiting.redraw()
+			}
+		})
+	})
+	waiting.Lock()
+	waiting.redraw = redraw
+	waiting.Unlock()
+	return func() {
+		waiting.Lock()
+		waiting.redraw = nil
+		waiting.Unlock()
+	}
+}
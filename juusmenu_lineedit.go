@@ -0,0 +1,504 @@
+package juusmenu
+
+//Line editing: with MenuOptions.SetLineEditor(true), and input and output both
+//a terminal, the menu prompt and GetUserInput prompts read input with a small
+//editor instead of a line at a time. Supported keys:
+//
+//  <Left> <Right> ^B ^F              move a character
+//  <Ctrl-Left> <Ctrl-Right> M-b M-f  move a word
+//  <Home> <End> ^A ^E                move to start, end of line
+//  <BS> <Del> ^D                     delete a character (^D on an empty line ends input)
+//  ^W M-<BS>, M-d                    delete the word before, after the cursor
+//  ^U ^K                             delete to start, end of line
+//  <Up> <Down> ^P ^N                 the prompt's input history
+//  <Tab>                             complete a Key of the menu, or a reserved command
+//
+//If the terminal can't be put in raw mode input is read line by line as always.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+//control keys the editor handles
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+//lineEditor : the state of one line being edited
+type lineEditor struct {
+	prompt string
+	//secret : echo '*' for each character typed
+	secret bool
+	//history : earlier inputs, oldest first, recalled with <Up> and <Down>
+	history []string
+	histPos int
+	//complete : returns the completions of a prefix, nil for no completion
+	complete func(prefix string) []string
+	//resized : if not nil, called before the line is redrawn when the terminal is resized
+	resized func()
+
+	buf     []rune
+	pos     int
+	unsaved []rune
+	out     io.Writer
+}
+
+//read : internal use, reads a line with the editor if it can, otherwise with readLine()
+func (ed *lineEditor) read() (string, bool) {
+	if !MenuOptions.lineEditor || pendingScan != nil || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return readLine()
+	}
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return readLine()
+	}
+	defer restore()
+	defer whileWaiting(func() {
+		if ed.resized != nil {
+			ed.resized()
+		}
+		ed.redraw()
+	})()
+	return ed.edit(stdinRunes{}, os.Stdout)
+}
+
+//edit : internal use, edits a line read from in, drawing it on out. The prompt must
+//already be shown. ok is false if input ended.
+func (ed *lineEditor) edit(in io.RuneReader, out io.Writer) (line string, ok bool) {
+	ed.out = out
+	ed.buf = ed.buf[:0]
+	ed.pos = 0
+	ed.histPos = len(ed.history)
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			fmt.Fprint(out, "\r\n")
+			return string(ed.buf), len(ed.buf) > 0
+		}
+		waiting.Lock()
+		line, ok, done := ed.key(in, r)
+		waiting.Unlock()
+		if done {
+			return line, ok
+		}
+	}
+}
+
+//key : internal use, handles key r for edit(), done is true when edit() should
+//return line and ok
+func (ed *lineEditor) key(in io.RuneReader, r rune) (line string, ok, done bool) {
+	switch r {
+	case keyCR, keyLF:
+		fmt.Fprint(ed.out, "\r\n")
+		return string(ed.buf), true, true
+	case keyCtrlC:
+		fmt.Fprint(ed.out, "^C\r\n")
+		interrupt()
+		return "", false, true
+	case keyCtrlD:
+		if len(ed.buf) == 0 {
+			fmt.Fprint(ed.out, "\r\n")
+			return "", false, true
+		}
+		ed.deleteRange(ed.pos, ed.pos+1)
+	case keyCtrlA:
+		ed.moveTo(0)
+	case keyCtrlE:
+		ed.moveTo(len(ed.buf))
+	case keyCtrlB:
+		ed.moveTo(ed.pos - 1)
+	case keyCtrlF:
+		ed.moveTo(ed.pos + 1)
+	case keyBackspace, keyCtrlH:
+		ed.deleteRange(ed.pos-1, ed.pos)
+	case keyCtrlW:
+		ed.deleteRange(ed.wordLeft(), ed.pos)
+	case keyCtrlU:
+		ed.deleteRange(0, ed.pos)
+	case keyCtrlK:
+		ed.deleteRange(ed.pos, len(ed.buf))
+	case keyCtrlP:
+		ed.recall(-1)
+	case keyCtrlN:
+		ed.recall(1)
+	case keyTab:
+		ed.completeLine()
+	case keyEscape:
+		ed.escape(in)
+	default:
+		if unicode.IsPrint(r) {
+			ed.insert(r)
+		}
+	}
+	return "", false, false
+}
+
+//escape : internal use, handles an escape sequence or an Alt (Meta) key
+func (ed *lineEditor) escape(in io.RuneReader) {
+	r, params, ok := readEscape(in)
+	if !ok {
+		return
+	}
+	ctrl := strings.HasSuffix(params, ";5")
+	switch r {
+	case 'b':
+		ed.moveTo(ed.wordLeft())
+		return
+	case 'f':
+		ed.moveTo(ed.wordRight())
+		return
+	case 'd':
+		ed.deleteRange(ed.pos, ed.wordRight())
+		return
+	case keyBackspace, keyCtrlH:
+		ed.deleteRange(ed.wordLeft(), ed.pos)
+		return
+	}
+	if params == "" {
+		//Alt with a key not handled
+		return
+	}
+	switch r {
+	case 'A':
+		ed.recall(-1)
+	case 'B':
+		ed.recall(1)
+	case 'C':
+		if ctrl {
+			ed.moveTo(ed.wordRight())
+		} else {
+			ed.moveTo(ed.pos + 1)
+		}
+	case 'D':
+		if ctrl {
+			ed.moveTo(ed.wordLeft())
+		} else {
+			ed.moveTo(ed.pos - 1)
+		}
+	case 'H':
+		ed.moveTo(0)
+	case 'F':
+		ed.moveTo(len(ed.buf))
+	case '~':
+		switch params {
+		case "[1", "[7":
+			ed.moveTo(0)
+		case "[4", "[8":
+			ed.moveTo(len(ed.buf))
+		case "[3":
+			ed.deleteRange(ed.pos, ed.pos+1)
+		}
+	}
+}
+
+//readEscape : internal use, reads what follows an <Esc>. For a CSI or SS3 sequence,
+//as sent by arrow and function keys, r is its final character and params what
+//came before it ("[" or "O" at least). Otherwise r is the key pressed with Alt.
+func readEscape(in io.RuneReader) (r rune, params string, ok bool) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return 0, "", false
+	}
+	if r != '[' && r != 'O' {
+		return r, "", true
+	}
+	seq := []rune{r}
+	for {
+		if r, _, err = in.ReadRune(); err != nil {
+			return 0, "", false
+		}
+		if r >= 0x40 && r <= 0x7e {
+			return r, string(seq), true
+		}
+		seq = append(seq, r)
+	}
+}
+
+//insert : internal use, inserts r at the cursor
+func (ed *lineEditor) insert(r rune) {
+	ed.buf = append(ed.buf, 0)
+	copy(ed.buf[ed.pos+1:], ed.buf[ed.pos:])
+	ed.buf[ed.pos] = r
+	ed.pos++
+	ed.redraw()
+}
+
+//deleteRange : internal use, deletes buf[from:to], clipped to the line
+func (ed *lineEditor) deleteRange(from, to int) {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(ed.buf) {
+		to = len(ed.buf)
+	}
+	if from >= to {
+		return
+	}
+	ed.buf = append(ed.buf[:from], ed.buf[to:]...)
+	ed.pos = from
+	ed.redraw()
+}
+
+//moveTo : internal use, moves the cursor, clipped to the line
+func (ed *lineEditor) moveTo(pos int) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(ed.buf) {
+		pos = len(ed.buf)
+	}
+	ed.pos = pos
+	ed.redraw()
+}
+
+//wordLeft : internal use, the start of the word before the cursor
+func (ed *lineEditor) wordLeft() int {
+	i := ed.pos
+	for i > 0 && unicode.IsSpace(ed.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(ed.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+//wordRight : internal use, the end of the word after the cursor
+func (ed *lineEditor) wordRight() int {
+	i := ed.pos
+	for i < len(ed.buf) && unicode.IsSpace(ed.buf[i]) {
+		i++
+	}
+	for i < len(ed.buf) && !unicode.IsSpace(ed.buf[i]) {
+		i++
+	}
+	return i
+}
+
+//recall : internal use, steps through the history, step -1 is older. Stepping past
+//the newest input brings back the line that was being typed.
+func (ed *lineEditor) recall(step int) {
+	next := ed.histPos + step
+	if next < 0 || next > len(ed.history) {
+		return
+	}
+	if ed.histPos == len(ed.history) {
+		ed.unsaved = append(ed.unsaved[:0], ed.buf...)
+	}
+	ed.histPos = next
+	if next == len(ed.history) {
+		ed.buf = append(ed.buf[:0], ed.unsaved...)
+	} else {
+		ed.buf = append(ed.buf[:0], []rune(ed.history[next])...)
+	}
+	ed.moveTo(len(ed.buf))
+}
+
+//completeLine : internal use, completes the line typed so far. A single completion
+//replaces it, several extend it to what they have in common, or are listed if
+//there is nothing in common to add.
+func (ed *lineEditor) completeLine() {
+	if ed.complete == nil || ed.secret {
+		return
+	}
+	prefix := string(ed.buf[:ed.pos])
+	matches := ed.complete(prefix)
+	if len(matches) == 0 {
+		return
+	}
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if common != prefix {
+		ed.buf = append([]rune(common), ed.buf[ed.pos:]...)
+		ed.moveTo(utf8.RuneCountInString(common))
+		return
+	}
+	if len(matches) > 1 {
+		fmt.Fprint(ed.out, "\r\n"+strings.Join(matches, "  ")+"\r\n")
+		ed.redraw()
+	}
+}
+
+//redraw : internal use, draws the prompt and line again, with the cursor in place
+func (ed *lineEditor) redraw() {
+	if ed.out == nil {
+		return
+	}
+	shown := string(ed.buf)
+	if ed.secret {
+		shown = strings.Repeat("*", len(ed.buf))
+	}
+	fmt.Fprint(ed.out, "\r"+ed.prompt+shown+"\x1b[K")
+	back := len(ed.buf) - ed.pos
+	if !ed.secret {
+		back = displayWidth(string(ed.buf[ed.pos:]))
+	}
+	if back > 0 {
+		fmt.Fprintf(ed.out, "\x1b[%dD", back)
+	}
+}
+
+//completions : internal use, the Menu's Keys and reserved commands starting with
+//prefix, in Menu order, for the line editor
+func (menu *Menu) completions(prefix string) []string {
+	var result []string
+	add := func(s string) {
+		if s != "" && strings.HasPrefix(s, prefix) {
+			result = append(result, s)
+		}
+	}
+	for _, id := range menu.sortKeys {
+		if entry := menu.entries[id]; !entry.hidden {
+			add(entry.value)
+		}
+	}
+	var reserved []string
+	if menu.checklist != nil {
+		reserved = append(reserved, checkAllKey, checkNoneKey, checkInvertKey)
+	}
+	if MenuOptions.historyLimit > 0 {
+		reserved = append(reserved, historyListCmd, historyLastCmd)
+	}
+	if menu.pageSize > 0 {
+		reserved = append(reserved, pageNextCmd, pagePrevCmd)
+	}
+	reserved = append(reserved, MenuOptions.killPhrase)
+	sort.Strings(reserved)
+	for _, s := range reserved {
+		add(s)
+	}
+	return result
+}
+
+//stdinRunes : internal use, reads os.Stdin a rune at a time without buffering, so
+//nothing typed ahead is lost to menuScanner once the editor is done
+type stdinRunes struct{}
+
+func (stdinRunes) ReadRune() (r rune, size int, err error) {
+	var b [utf8.UTFMax]byte
+	if _, err = os.Stdin.Read(b[:1]); err != nil {
+		return 0, 0, err
+	}
+	n := 1
+	for !utf8.FullRune(b[:n]) && n < utf8.UTFMax {
+		if _, err = os.Stdin.Read(b[n : n+1]); err != nil {
+			return 0, 0, err
+		}
+		n++
+	}
+	r, size = utf8.DecodeRune(b[:n])
+	return r, size, nil
+}
+
+//editLineTimeout : internal use, readLineTimeout on a raw terminal. The first key
+//typed stops the countdown, the rest of the line is read with the line editor.
+func editLineTimeout(timeout time.Duration, countdown func(secondsLeft int) string) (text string, ok, timedOut bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return "", true, true
+		}
+		fmt.Print("\r" + countdown(secondsLeft(left)) + ansiClearLine)
+		//wait for a key until the next second is due
+		wait := left - time.Duration(secondsLeft(left)-1)*time.Second
+		if err := readTimeout(os.Stdin, wait); err != nil {
+			return "", false, false
+		}
+		r, _, err := stdinRunes{}.ReadRune()
+		if err == io.EOF {
+			continue
+		}
+		readTimeout(os.Stdin, 0)
+		if err != nil {
+			return "", false, false
+		}
+		ed := &lineEditor{prompt: countdown(0), out: os.Stdout}
+		ed.redraw()
+		text, ok = ed.edit(&typedAhead{r: r, in: stdinRunes{}}, os.Stdout)
+		return text, ok, false
+	}
+}
+
+//typedAhead : internal use, reads r and then the runes of in
+type typedAhead struct {
+	r    rune
+	read bool
+	in   io.RuneReader
+}
+
+func (t *typedAhead) ReadRune() (r rune, size int, err error) {
+	if !t.read {
+		t.read = true
+		return t.r, utf8.RuneLen(t.r), nil
+	}
+	return t.in.ReadRune()
+}
+
+//readKey : internal use, reads a single key press, for single key Menus. isRaw is
+//false if the terminal can't be put in raw mode, nothing is read then. <RET> reads
+//as "", ok is false if input ended. The key is echoed after the prompt.
+func readKey() (key string, ok, isRaw bool) {
+	if pendingScan != nil || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return "", false, false
+	}
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return "", false, false
+	}
+	defer restore()
+	return keyPress(stdinRunes{}, os.Stdout)
+}
+
+//keyPress : internal use, reads a key press from in for readKey, skipping the
+//escape sequences of arrow and function keys
+func keyPress(in io.RuneReader, out io.Writer) (key string, ok, isRaw bool) {
+	for {
+		r, _, err := in.ReadRune()
+		switch {
+		case err != nil || r == keyCtrlD:
+			fmt.Fprint(out, "\r\n")
+			return "", false, true
+		case r == keyCtrlC:
+			fmt.Fprint(out, "^C\r\n")
+			interrupt()
+			return "", false, true
+		case r == keyCR || r == keyLF:
+			fmt.Fprint(out, "\r\n")
+			return "", true, true
+		case r == keyEscape:
+			readEscape(in)
+		case unicode.IsPrint(r):
+			fmt.Fprint(out, string(r)+"\r\n")
+			return string(r), true, true
+		}
+	}
+}
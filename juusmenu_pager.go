@@ -0,0 +1,416 @@
+package juusmenu
+
+//Pager: the output of an entry func() can be kept until the func() returns and
+//then shown a screen at a time, between the func brackets, instead of scrolling
+//away. Set it for all entries with MenuOptions.SetOutputMode() or for one entry
+//with Menu.SetEntryOutput(). Output that fits the terminal, or that does not go
+//to a terminal, is printed as usual. The keys of the built-in pager:
+//
+//  <Space> f ^F <PgDn>    the next page
+//  b ^B <PgUp>            the previous page
+//  <RET> j ^N <Down>      the next line
+//  k ^P <Up>              the previous line
+//  d u                    half a page down, up
+//  g <Home>, G <End>      the first, last page
+//  /text ?text            search forward, backward, ignoring case
+//  n N                    the next, previous match
+//  h                      help
+//  q                      back to the menu
+//
+//While its output is kept a func() should not ask for input: its prompt would
+//only show once the func() returned, so the func() seems to hang. os.Stdout is
+//replaced while the func() runs, whatever other goroutines print meanwhile is
+//kept with its output.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//OutputMode : where the output of an entry func() goes, see MenuOptions.SetOutputMode()
+type OutputMode int
+
+const (
+	//OutputDefault : Default. For an entry, as set with MenuOptions.SetOutputMode(),
+	//for MenuOptions the same as OutputDirect
+	OutputDefault OutputMode = iota
+	//OutputDirect : printed while the func() runs
+	OutputDirect
+	//OutputPager : kept until the func() returns, then shown in the built-in pager
+	OutputPager
+	//OutputPagerEnv : as OutputPager, but shown with the $PAGER program if it is set
+	OutputPagerEnv
+)
+
+func (om OutputMode) String() string {
+	return enumString("OutputMode", int(om), "OutputDefault", "OutputDirect", "OutputPager", "OutputPagerEnv")
+}
+
+const (
+	pagerStatusFormat = "%s  lines %d-%d of %d (%d%%)  h help, q back to the menu"
+	pagerHelp         = "<Space> b page  <RET> k line  g G first, last  /text ?text search  n N match  q quit"
+	pagerNotFound     = "Not found: '%s'"
+	tabWidth          = 8
+)
+
+//SetOutputMode : Sets where the output of entry func()'s goes, see OutputMode.
+//An entry can have its own, see Menu.SetEntryOutput(). The output of SubMenus,
+//toggle, cycle and counter entries, and in full screen mode, is always printed
+//directly. Entries that prompt for input must print directly: a kept prompt is not
+//shown until the func() returns.
+func (mo *menuOptions) SetOutputMode(mode OutputMode) {
+	mo.outputMode = mode
+}
+
+//SetEntryOutput : Sets where the output of the entry's func() goes, see OutputMode.
+//OutputDefault goes back to the MenuOptions output mode. Set OutputDirect for an
+//entry that prompts for input, a kept prompt is not shown until the func() returns.
+func (menu *Menu) SetEntryOutput(key string, mode OutputMode) error {
+	entry, err := menu.predicateEntry("SetEntryOutput", key)
+	if err != nil {
+		return err
+	}
+	entry.output = mode
+	return nil
+}
+
+//outputMode : internal use, where the output of the entry's func() goes now
+func (entry *menuEntry) outputMode() OutputMode {
+	mode := entry.output
+	if mode == OutputDefault {
+		mode = MenuOptions.outputMode
+	}
+	if mode == OutputDefault || !entry.isBracketed() || screen.active {
+		return OutputDirect
+	}
+	return mode
+}
+
+//run : internal use, runs the entry's func(), paging its output if it is set to.
+//title is shown by the built-in pager.
+func (entry *menuEntry) run(title string) {
+	mode := entry.outputMode()
+	if mode == OutputDirect || !isTerminal(os.Stdout) {
+		entry.doRun()
+		return
+	}
+	showOutput(captureOutput(entry.doRun), title, mode == OutputPagerEnv)
+}
+
+//captureOutput : internal use, runs run with os.Stdout going to a buffer, returns
+//what it printed
+func captureOutput(run func()) (output string) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		run()
+		return ""
+	}
+	var b bytes.Buffer
+	copied := make(chan bool)
+	go func() {
+		io.Copy(&b, r)
+		r.Close()
+		close(copied)
+	}()
+	waiting.Lock()
+	saved := os.Stdout
+	os.Stdout, waiting.captured = w, true
+	waiting.Unlock()
+	defer func() {
+		waiting.Lock()
+		os.Stdout, waiting.captured = saved, false
+		waiting.Unlock()
+		w.Close()
+		<-copied
+		output = b.String()
+	}()
+	run()
+	return ""
+}
+
+//showOutput : internal use, shows output a page at a time, with $PAGER if usePager
+//and it is set, otherwise with the built-in pager. Printed as is if it fits.
+func showOutput(output, title string, usePager bool) {
+	if output == "" {
+		return
+	}
+	p := &pager{title: title, lines: strings.Split(strings.TrimSuffix(output, "\n"), "\n"), out: os.Stdout}
+	p.resize()
+	if len(p.shown) <= p.rows {
+		fmt.Print(output)
+		return
+	}
+	if program := strings.Fields(os.Getenv("PAGER")); usePager && len(program) > 0 {
+		cmd := exec.Command(program[0], program[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(output), os.Stdout, os.Stderr
+		//if $PAGER fails the built-in pager shows the output
+		if cmd.Run() == nil {
+			return
+		}
+	}
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		fmt.Print(output)
+		return
+	}
+	defer restore()
+	fmt.Fprint(p.out, "\x1b[?1049h")
+	defer fmt.Fprint(p.out, "\x1b[?1049l")
+	defer whileWaiting(func() {
+		p.resize()
+		p.draw()
+	})()
+	p.run(stdinRunes{})
+}
+
+//pager : the built-in pager
+type pager struct {
+	title string
+	//lines : the output, shown the lines wrapped to the width
+	lines, shown []string
+	//top : the first line shown, rows the lines of output shown below each other
+	top, rows, cols int
+	//search : the text searched last, backward if back
+	search string
+	back   bool
+	//status : shown once instead of the position, for help and messages
+	status string
+	out    io.Writer
+}
+
+//resize : internal use, fits the pager to the terminal, or the default size
+func (p *pager) resize() {
+	cols, rows, err := terminalSize(os.Stdout)
+	if err != nil || cols <= 0 || rows <= 1 {
+		cols, rows = screenDefCols, screenDefRows
+	}
+	p.layout(cols, rows-1)
+}
+
+//layout : internal use, wraps the lines to cols columns, rows of them shown
+func (p *pager) layout(cols, rows int) {
+	p.cols, p.rows = cols, rows
+	p.shown = p.shown[:0]
+	for _, line := range p.lines {
+		line = expandTabs(line)
+		for displayWidth(line) > cols {
+			part := fitWidth(line, cols)
+			if part == "" {
+				_, size := utf8.DecodeRuneInString(line)
+				part = line[:size]
+			}
+			p.shown = append(p.shown, part)
+			line = line[len(part):]
+		}
+		p.shown = append(p.shown, line)
+	}
+	p.scroll(0)
+}
+
+//run : internal use, shows the output and handles the keys read from in until
+//the user quits
+func (p *pager) run(in io.RuneReader) {
+	p.draw()
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return
+		}
+		waiting.Lock()
+		quit := p.key(in, r)
+		if !quit {
+			p.draw()
+		}
+		waiting.Unlock()
+		if quit {
+			return
+		}
+	}
+}
+
+//key : internal use, handles key r, true if the user quits
+func (p *pager) key(in io.RuneReader, r rune) (quit bool) {
+	switch r {
+	case 'q', 'Q', keyCtrlC:
+		return true
+	case ' ', 'f', keyCtrlF:
+		p.scroll(p.rows)
+	case 'b', keyCtrlB:
+		p.scroll(-p.rows)
+	case keyCR, keyLF, 'j', keyCtrlN:
+		p.scroll(1)
+	case 'k', keyCtrlP:
+		p.scroll(-1)
+	case 'd':
+		p.scroll(p.rows / 2)
+	case 'u':
+		p.scroll(-p.rows / 2)
+	case 'g', '<':
+		p.scroll(-len(p.shown))
+	case 'G', '>':
+		p.scroll(len(p.shown))
+	case '/', '?':
+		if text, ok := p.readSearch(in, r); ok {
+			if text != "" {
+				p.search = text
+			}
+			p.back = r == '?'
+			p.find(p.back)
+		}
+	case 'n':
+		p.find(p.back)
+	case 'N':
+		p.find(!p.back)
+	case 'h':
+		p.status = pagerHelp
+	case keyEscape:
+		final, params, _ := readEscape(in)
+		switch {
+		case final == 'A':
+			p.scroll(-1)
+		case final == 'B':
+			p.scroll(1)
+		case params == "[5":
+			p.scroll(-p.rows)
+		case params == "[6":
+			p.scroll(p.rows)
+		case final == 'H' || params == "[1" || params == "[7":
+			p.scroll(-len(p.shown))
+		case final == 'F' || params == "[4" || params == "[8":
+			p.scroll(len(p.shown))
+		}
+	}
+	return false
+}
+
+//scroll : internal use, moves the lines shown by n, down for positive n, keeping
+//the last page full
+func (p *pager) scroll(n int) {
+	p.top = p.top + n
+	if last := len(p.shown) - p.rows; p.top > last {
+		p.top = last
+	}
+	if p.top < 0 {
+		p.top = 0
+	}
+}
+
+//readSearch : internal use, reads the text to search on the status line, ok is
+//false if the search is canceled
+func (p *pager) readSearch(in io.RuneReader, prompt rune) (text string, ok bool) {
+	var typed []rune
+	for {
+		fmt.Fprintf(p.out, ansiMoveTo+"%s%c%s", p.rows+1, ansiClearLine, prompt, string(typed))
+		r, _, err := in.ReadRune()
+		switch {
+		case err != nil || r == keyCtrlC:
+			return "", false
+		case r == keyCR || r == keyLF:
+			return string(typed), true
+		case r == keyEscape:
+			readEscape(in)
+			return "", false
+		case r == keyBackspace || r == keyCtrlH:
+			if len(typed) == 0 {
+				return "", false
+			}
+			typed = typed[:len(typed)-1]
+		case r == keyCtrlU:
+			typed = typed[:0]
+		case unicode.IsPrint(r):
+			typed = append(typed, r)
+		}
+	}
+}
+
+//find : internal use, moves to the next line with the text searched, ignoring case,
+//from the line below the first one shown, or above it if back
+func (p *pager) find(back bool) {
+	if p.search == "" {
+		return
+	}
+	text := strings.ToLower(p.search)
+	step := 1
+	if back {
+		step = -1
+	}
+	for i := p.top + step; i >= 0 && i < len(p.shown); i = i + step {
+		if strings.Contains(strings.ToLower(p.shown[i]), text) {
+			p.scroll(i - p.top)
+			return
+		}
+	}
+	p.status = fmt.Sprintf(pagerNotFound, p.search)
+}
+
+//draw : internal use, draws the lines shown and the status line
+func (p *pager) draw() {
+	var b bytes.Buffer
+	b.WriteString("\x1b[H")
+	for i := 0; i < p.rows; i++ {
+		line := "~"
+		if n := p.top + i; n < len(p.shown) {
+			line = p.highlight(p.shown[n])
+		}
+		b.WriteString(ansiClearLine + line + "\r\n")
+	}
+	status := p.status
+	if status == "" {
+		last := p.top + p.rows
+		if last > len(p.shown) {
+			last = len(p.shown)
+		}
+		status = fmt.Sprintf(pagerStatusFormat, p.title, p.top+1, last, len(p.shown), last*100/len(p.shown))
+	}
+	p.status = ""
+	b.WriteString(ansiClearLine + ansiReverse + fitWidth(status, p.cols) + ansiReset)
+	p.out.Write(b.Bytes())
+}
+
+//highlight : internal use, line with the text searched in reverse video
+func (p *pager) highlight(line string) string {
+	text := strings.ToLower(p.search)
+	lower := strings.ToLower(line)
+	if text == "" || len(lower) != len(line) {
+		return line
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, text)
+		if i < 0 {
+			break
+		}
+		b.WriteString(line[:i] + ansiReverse + line[i:i+len(text)] + ansiReset)
+		line, lower = line[i+len(text):], lower[i+len(text):]
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+//expandTabs : internal use, line with its tabs replaced by spaces, to tabWidth columns
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for i, r := range line {
+		if r == '\t' {
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col = col + n
+			continue
+		}
+		//the bytes of line, not r, so output that is not valid UTF-8 is kept as is
+		_, size := utf8.DecodeRuneInString(line[i:])
+		b.WriteString(line[i : i+size])
+		col = col + runeWidth(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,206 @@
+package juusmenu
+
+//Paging: Menus with many entries, say generated from database rows, show a page
+//of them at a time, see Menu.SetPageSize(). The header shows the page, and at the
+//prompt:
+//
+//  n        the next page
+//  p        the previous page
+//  /text    shows only the entries whose Key or hint contains text, ignoring
+//           case. Another /text narrows them further.
+//  /        shows all entries again
+//
+//Keys not shown can still be typed. A filter goes through the Menu's sorted entries
+//once, the next one only through the entries left, and both are kept until the
+//filter is cleared or the Menu changes, so even very large Menus filter quickly.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	pageNextCmd        = "n"
+	pagePrevCmd        = "p"
+	filterCmd          = "/"
+	pageFormat         = "page %d/%d"
+	filterFormat       = ", %d matching %s"
+	pagingFooterFormat = "'%s' next  '%s' previous page  '%stext' filter  '%s' all"
+)
+
+//SetPageSize : Shows the Menu's entries size at a time, with commands to page
+//through and filter them, for Menus of hundreds or thousands of entries. 0, the
+//default, shows all entries at once. In a paged Menu Keys 'n', 'p' and those
+//starting with '/' are taken by the commands, auto keys skip them.
+func (menu *Menu) SetPageSize(size int) error {
+	if size < 0 {
+		errmsg := warn + fmt.Sprintf("SetPageSize(): Menu '%s' can not have pages of %d entries, page size not changed.", menu.Title, size)
+		alertUser(&errmsg)
+		return errors.New(errmsg)
+	}
+	menu.pageSize = size
+	menu.isModified = menu.finalized
+	return nil
+}
+
+//pagingCommand : internal use, handles the paging commands typed at the menu
+//prompt, true if input was one
+func (menu *Menu) pagingCommand(input string) bool {
+	if menu.pageSize <= 0 {
+		return false
+	}
+	switch {
+	case input == pageNextCmd:
+		//pageKeys() keeps it to the last page
+		menu.page++
+	case input == pagePrevCmd:
+		if menu.page > 0 {
+			menu.page--
+		}
+	case input == filterCmd:
+		menu.clearFilter()
+	case strings.HasPrefix(input, filterCmd):
+		menu.filter = append(menu.filter, strings.TrimPrefix(input, filterCmd))
+		menu.page = 0
+	default:
+		return false
+	}
+	return true
+}
+
+//clearFilter : internal use, shows all entries again, from the first page
+func (menu *Menu) clearFilter() {
+	menu.filter, menu.filtered, menu.page = nil, nil, 0
+}
+
+//filterKeys : internal use, the ids of the sorted entries matching every text of
+//the filter. The matches of each text are kept, later texts only go through them.
+func (menu *Menu) filterKeys() []string {
+	ids := menu.sortKeys
+	for i, text := range menu.filter {
+		if i < len(menu.filtered) {
+			ids = menu.filtered[i]
+			continue
+		}
+		text = strings.ToLower(text)
+		matches := []string{}
+		for _, id := range ids {
+			entry := menu.entries[id]
+			if id != breakIndicator && (strings.Contains(strings.ToLower(entry.value), text) ||
+				strings.Contains(strings.ToLower(entry.hint), text)) {
+				matches = append(matches, id)
+			}
+		}
+		menu.filtered = append(menu.filtered, matches)
+		ids = matches
+	}
+	return ids
+}
+
+//pageKeys : internal use, the ids of the entries on the page shown, in order,
+//without the Break Item and hidden entries, and the number of entries on all
+//pages. A page past the last one is moved back to the last one.
+func (menu *Menu) pageKeys() (keys []string, count int) {
+	ids := menu.sortKeys
+	if len(menu.filter) > 0 {
+		ids = menu.filterKeys()
+	}
+	first := menu.page * menu.pageSize
+	for _, id := range ids {
+		if id == breakIndicator || menu.entries[id].hidden {
+			continue
+		}
+		if count >= first && count < first+menu.pageSize {
+			keys = append(keys, id)
+		}
+		count++
+	}
+	if menu.page > 0 && first >= count {
+		menu.page = (count - 1) / menu.pageSize
+		if menu.page < 0 {
+			menu.page = 0
+		}
+		return menu.pageKeys()
+	}
+	return keys, count
+}
+
+//pagedView : internal use, fills in the page and filter of view, returns the ids
+//of the entries on the page shown with the Break Item in its place
+func (menu *Menu) pagedView(view *MenuView) []string {
+	keys, count := menu.pageKeys()
+	view.Page, view.Pages = menu.page+1, (count+menu.pageSize-1)/menu.pageSize
+	if view.Pages == 0 {
+		view.Pages = 1
+	}
+	view.Filter, view.Matches = menu.filter, count
+	if len(menu.sortKeys) > 0 && menu.sortKeys[0] == breakIndicator {
+		return append([]string{breakIndicator}, keys...)
+	}
+	return append(keys, breakIndicator)
+}
+
+//validatePaging : internal use, doValidate report on Keys taken by the paging commands
+func (menu *Menu) validatePaging() string {
+	const keysCommand = ">> Paged Menu '%s' has a Key '%s' which is taken by a paging command, the Entry can not be chosen\n"
+	//auto keyed menus skip the paging commands, their IDs don't matter
+	if menu.autoKeys != AutoKeyNone {
+		return ""
+	}
+	var keys []string
+	for id, entry := range menu.entries {
+		if id != breakIndicator && isPagingKey(entry.value) {
+			keys = append(keys, entry.value)
+		}
+	}
+	if isPagingKey(menu.quitValue) {
+		keys = append(keys, menu.quitValue)
+	}
+	sort.Strings(keys)
+	report := ""
+	for _, key := range keys {
+		report = report + fmt.Sprintf(keysCommand, menu.Title, key)
+	}
+	return report
+}
+
+//isPagingKey : internal use, true if key is taken by a paging command
+func isPagingKey(key string) bool {
+	return key == pageNextCmd || key == pagePrevCmd || strings.HasPrefix(key, filterCmd)
+}
+
+//pageIndicator : internal use, the page and filter shown in the header, "" if the
+//Menu is not paged
+func (view *MenuView) pageIndicator() string {
+	if view.Pages == 0 {
+		return ""
+	}
+	indicator := fmt.Sprintf(pageFormat, view.Page, view.Pages)
+	if len(view.Filter) > 0 {
+		quoted := make([]string, len(view.Filter))
+		for i, text := range view.Filter {
+			quoted[i] = "'" + text + "'"
+		}
+		indicator = indicator + fmt.Sprintf(filterFormat, view.Matches, strings.Join(quoted, " "))
+	}
+	return "[" + indicator + "]"
+}
+
+//header : internal use, the breadcrumbs line, with the page indicator of paged Menus
+func (view *MenuView) header() string {
+	header := strings.Join(view.Breadcrumbs, " "+view.Separator+" ")
+	if indicator := view.pageIndicator(); indicator != "" {
+		header = header + "  " + indicator
+	}
+	return header
+}
+
+//pagingFooter : internal use, the line of paging commands, "" if the Menu is not paged
+func (view *MenuView) pagingFooter() string {
+	if view.Pages == 0 {
+		return ""
+	}
+	return fmt.Sprintf(pagingFooterFormat, pageNextCmd, pagePrevCmd, filterCmd, filterCmd)
+}
@@ -0,0 +1,438 @@
+package juusmenu
+
+//Renderers: displayMenu gives a Renderer a MenuView, everything a Menu shows,
+//and the Renderer lays it out. Set one for all Menus with MenuOptions.SetRenderer()
+//or for one Menu with Menu.SetRenderer(). Full screen mode draws itself.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Renderer : lays out a Menu. Render writes the view to w, including the prompt
+//unless the layout is not meant for people. If Render returns an error the
+//user is alerted and the Menu is shown with the classic layout instead.
+type Renderer interface {
+	Render(w io.Writer, view *MenuView) error
+}
+
+//MenuView : what a Menu shows, as given to a Renderer
+type MenuView struct {
+	//Breadcrumbs : the Titles of the root Menu down to this one, joined by Separator
+	Breadcrumbs []string `json:"breadcrumbs"`
+	Separator   string   `json:"separator"`
+	Title       string   `json:"title"`
+	//Entries : the Menu Entries in the order shown, without hidden ones and the Break Item
+	Entries []EntryView `json:"entries"`
+	//Quit : the Break Item, shown first if QuitFirst (descending sort), otherwise last
+	Quit      EntryView `json:"quit"`
+	QuitFirst bool      `json:"quitFirst,omitempty"`
+	//KillPhrase : "" if there is none
+	KillPhrase string `json:"killPhrase,omitempty"`
+	//Checklist : for checklist Menus, the line with the commands and marked count
+	Checklist string `json:"checklist,omitempty"`
+	//Default : the Key <RET> chooses, "" if none
+	Default    string `json:"default,omitempty"`
+	Prompt     string `json:"prompt"`
+	AlignRight bool   `json:"-"`
+	//Theme : see MenuOptions.SetTheme(), its Styles Paint() only when colours are on
+	Theme Theme `json:"-"`
+	//Width : the width to lay out for, 0 if there is no limit, see MenuOptions.SetLineWidth()
+	Width     int          `json:"-"`
+	LongHints LongHintMode `json:"-"`
+	//Height : the lines of the terminal, 0 if unknown
+	Height int `json:"-"`
+	//Columns : see Menu.SetColumns(), 0 is as many as needed
+	Columns int `json:"-"`
+	//Page, Pages : for paged Menus the page shown, from 1, and the number of pages,
+	//see Menu.SetPageSize(). Pages is 0 if the Menu is not paged.
+	Page  int `json:"page,omitempty"`
+	Pages int `json:"pages,omitempty"`
+	//Filter : the texts the entries are filtered by, Matches the number of entries
+	//matching all of them, on all pages
+	Filter  []string `json:"filter,omitempty"`
+	Matches int      `json:"matches,omitempty"`
+}
+
+//EntryView : one Menu Entry as shown
+type EntryView struct {
+	Key  string `json:"key"`
+	Hint string `json:"hint"`
+	//Badge : see Menu.SetEntryBadge(), "" if none
+	Badge string `json:"badge,omitempty"`
+	//Section : the Title of the entry's section, NewSection is true for the first entry
+	//of each section. Entries without section come first, with Section "".
+	Section        string `json:"section,omitempty"`
+	NewSection     bool   `json:"newSection,omitempty"`
+	Disabled       bool   `json:"disabled,omitempty"`
+	DisabledReason string `json:"disabledReason,omitempty"`
+	//Checkable, Checked : checklist items and their mark
+	Checkable bool `json:"checkable,omitempty"`
+	Checked   bool `json:"checked,omitempty"`
+	SubMenu   bool `json:"subMenu,omitempty"`
+}
+
+//SetRenderer : Sets the Renderer showing this Menu, nil for MenuOptions' renderer.
+func (menu *Menu) SetRenderer(r Renderer) {
+	menu.renderer = r
+}
+
+//view : internal use, the MenuView of the Menu as it is now
+func (menu *Menu) view() *MenuView {
+	view := &MenuView{
+		Breadcrumbs: menu.crumbs(),
+		Separator:   MenuOptions.menuSeparator,
+		Title:       menu.displayTitle(),
+		KillPhrase:  MenuOptions.killPhrase,
+		Prompt:      MenuOptions.menuPrompt,
+		AlignRight:  alignRight,
+		Theme:       MenuOptions.theme,
+		Width:       lineWidth(),
+		LongHints:   MenuOptions.longHints,
+		Height:      lineHeight(),
+		Columns:     menu.columns,
+	}
+	if menu.checklist != nil {
+		view.Checklist = menu.checklistFooter()
+	}
+	if def := menu.defaultInput(); def != "" && menu.blankInput() == BlankDefault {
+		view.Default = def
+	}
+	keys := menu.sortKeys
+	if menu.pageSize > 0 {
+		keys = menu.pagedView(view)
+	}
+	section := ""
+	for i, k := range keys {
+		entry := menu.entries[k]
+		if entry.hidden {
+			continue
+		}
+		ev := EntryView{
+			Key:            entry.value,
+			Hint:           entry.displayHint(),
+			Badge:          entry.displayBadge(),
+			Disabled:       entry.disabled,
+			DisabledReason: entry.disabledReason,
+			SubMenu:        entry.isSubMenuEntry,
+		}
+		if k == breakIndicator {
+			view.Quit = ev
+			view.QuitFirst = i == 0 && len(keys) > 1
+			continue
+		}
+		if idx := menu.sectionIndex(entry.section); idx >= 0 {
+			ev.Section = menu.sections[idx].title
+			ev.NewSection = entry.section != section
+		}
+		section = entry.section
+		if menu.checklist != nil {
+			ev.Checkable, ev.Checked = true, entry.checked
+		}
+		view.Entries = append(view.Entries, ev)
+	}
+	return view
+}
+
+//rendererInUse : internal use, the Renderer in effect for the Menu
+func (menu *Menu) rendererInUse() Renderer {
+	switch {
+	case menu.renderer != nil:
+		return menu.renderer
+	case MenuOptions.renderer != nil:
+		return MenuOptions.renderer
+	}
+	return ClassicRenderer{}
+}
+
+//render : internal use, shows the Menu on w with its Renderer, falling back to the
+//classic layout if the Renderer fails. Nothing of a failed layout is shown.
+func (menu *Menu) render(w io.Writer) {
+	view := menu.view()
+	r := menu.rendererInUse()
+	var b bytes.Buffer
+	if err := r.Render(&b, view); err != nil {
+		errmsg := warn + fmt.Sprintf("Menu '%s': renderer %s failed, showing the classic layout: %s", menu.Title, rendererName(r), err)
+		alertUser(&errmsg)
+		b.Reset()
+		ClassicRenderer{}.Render(&b, view)
+	}
+	w.Write(b.Bytes())
+}
+
+//rendererName : internal use, names a Renderer for messages
+func rendererName(r Renderer) string {
+	if r == nil {
+		return "ClassicRenderer"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", r), "juusmenu.")
+}
+
+//ordered : internal use, the entries with the Break Item in its place
+func (view *MenuView) ordered() []EntryView {
+	if view.QuitFirst {
+		return append([]EntryView{view.Quit}, view.Entries...)
+	}
+	return append(append([]EntryView(nil), view.Entries...), view.Quit)
+}
+
+//sectionHeader : internal use, the header shown above an entry starting a section, or ""
+func (ev EntryView) sectionHeader() string {
+	switch {
+	case !ev.NewSection:
+		return ""
+	case ev.Section == "":
+		return separatorLine
+	}
+	return fmt.Sprintf(sectionFormat, ev.Section)
+}
+
+//listHint : internal use, the hint with the entry's check mark and why it is unavailable
+func (ev EntryView) listHint() string {
+	hint := ev.Hint
+	if ev.Checkable {
+		hint = checkMark(ev.Checked) + hint
+	}
+	if ev.Disabled {
+		hint = fmt.Sprintf(disabledFormat, hint, ev.DisabledReason)
+	}
+	return hint
+}
+
+//footerLines : internal use, the lines shown below the entries
+func (view *MenuView) footerLines() []string {
+	var lines []string
+	if view.Checklist != "" {
+		lines = append(lines, view.Checklist)
+	}
+	if paging := view.pagingFooter(); paging != "" {
+		lines = append(lines, paging)
+	}
+	if view.KillPhrase != "" {
+		lines = append(lines, fmt.Sprintf(killTemplate, view.KillPhrase))
+	} else {
+		lines = append(lines, "==============================")
+	}
+	if view.Default != "" {
+		lines = append(lines, fmt.Sprintf("<RET> chooses '%s'", view.Default))
+	}
+	return lines
+}
+
+//keyWidth : internal use, the width of the widest Key, and whether any entry has a badge
+func (view *MenuView) keyWidth() (width int, hasBadges bool) {
+	for _, ev := range view.ordered() {
+		if w := displayWidth(ev.Key); w > width {
+			width = w
+		}
+		if ev.Badge != "" {
+			hasBadges = true
+		}
+	}
+	return width, hasBadges
+}
+
+//ClassicRenderer : the layout of Menus by default, the breadcrumbs, a line, the aligned
+//Keys and hints and the kill phrase line
+type ClassicRenderer struct{}
+
+//Render : see Renderer
+func (ClassicRenderer) Render(w io.Writer, view *MenuView) error {
+	theme := view.Theme
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, theme.Breadcrumbs.Paint(view.header()))
+	fmt.Fprintln(w, "------------------------------")
+
+	//Keys are padded to a common width because section headers
+	//interrupt the aligner's columns
+	keyWidth, hasBadges := view.keyWidth()
+	padKey := padRight
+	if view.AlignRight {
+		padKey = padLeft
+	}
+	hintWidth := view.hintWidth(keyWidth)
+	var rows [][]string
+	for _, ev := range view.ordered() {
+		if header := ev.sectionHeader(); header != "" {
+			rows = append(rows, []string{theme.Section.Paint(header)})
+		}
+		style := theme.hintStyle(ev)
+		hint := fitHint(ev.listHint(), hintWidth, view.LongHints)
+		row := []string{theme.Key.Paint(padKey(ev.Key, keyWidth)), ": ", style.Paint(hint[0])}
+		if hasBadges {
+			//badges get their own column, empty badges keep the column aligned
+			row = append(row, "")
+			if ev.Badge != "" {
+				row[3] = fmt.Sprintf(badgeFormat, ev.Badge)
+			}
+		}
+		rows = append(rows, row)
+		//wrapped hint lines keep to the hint column
+		for _, more := range hint[1:] {
+			row := []string{strings.Repeat(" ", keyWidth), "  ", style.Paint(more)}
+			if hasBadges {
+				row = append(row, "")
+			}
+			rows = append(rows, row)
+		}
+	}
+	if cols := view.columnLayout(len(rows)); cols != nil {
+		view.renderColumns(w, cols)
+	} else {
+		for _, line := range alignCells(rows, 1, view.AlignRight) {
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	for _, line := range view.footerLines() {
+		fmt.Fprintln(w, theme.Footer.Paint(line))
+	}
+	fmt.Fprint(w, theme.Prompt.Paint(view.Prompt))
+	return nil
+}
+
+//hintWidth : internal use, the columns the classic layout leaves for hints,
+//0 if there is no limit
+func (view *MenuView) hintWidth(keyWidth int) int {
+	if view.Width <= 0 {
+		return 0
+	}
+	//"key : hint [badge]", with the columns padded by one
+	width := view.Width - (keyWidth + 1) - (2 + 1)
+	badges := 0
+	for _, ev := range view.ordered() {
+		if w := displayWidth(fmt.Sprintf(badgeFormat, ev.Badge)) + 1; ev.Badge != "" && w > badges {
+			badges = w
+		}
+	}
+	if width-badges < minHintWidth {
+		return minHintWidth
+	}
+	return width - badges
+}
+
+//hintStyle : internal use, the Style of an entry's hint
+func (theme Theme) hintStyle(ev EntryView) Style {
+	if ev.Disabled {
+		return theme.Disabled
+	}
+	return theme.Hint
+}
+
+//BoxedRenderer : the classic layout drawn in a box
+type BoxedRenderer struct{}
+
+//Render : see Renderer
+func (BoxedRenderer) Render(w io.Writer, view *MenuView) error {
+	theme := view.Theme
+	//lines are kept plain, to measure them, and painted, to show them
+	type boxLine struct{ plain, painted string }
+	keyWidth, _ := view.keyWidth()
+	var body []boxLine
+	for _, ev := range view.ordered() {
+		if header := ev.sectionHeader(); header != "" {
+			body = append(body, boxLine{header, theme.Section.Paint(header)})
+		}
+		key := padRight(ev.Key, keyWidth) + "  "
+		line := boxLine{key + ev.listHint(), theme.Key.Paint(key) + theme.hintStyle(ev).Paint(ev.listHint())}
+		if ev.Badge != "" {
+			line.plain = line.plain + "  " + fmt.Sprintf(badgeFormat, ev.Badge)
+			line.painted = line.painted + "  " + fmt.Sprintf(badgeFormat, ev.Badge)
+		}
+		body = append(body, line)
+	}
+	title := view.header()
+	var footer []string
+	if view.Checklist != "" {
+		footer = append(footer, view.Checklist)
+	}
+	if paging := view.pagingFooter(); paging != "" {
+		footer = append(footer, paging)
+	}
+	if view.KillPhrase != "" {
+		footer = append(footer, fmt.Sprintf("'%s' immediately exits all Menus", view.KillPhrase))
+	}
+	if view.Default != "" {
+		footer = append(footer, fmt.Sprintf("<RET> chooses '%s'", view.Default))
+	}
+
+	width := displayWidth(title)
+	for _, line := range body {
+		if n := displayWidth(line.plain); n > width {
+			width = n
+		}
+	}
+	for _, line := range footer {
+		if n := displayWidth(line); n > width {
+			width = n
+		}
+	}
+	rule := func(left, right string) {
+		fmt.Fprintln(w, left+strings.Repeat("─", width+2)+right)
+	}
+	row := func(plain, painted string) {
+		fmt.Fprintln(w, "│ "+painted+strings.Repeat(" ", width-displayWidth(plain))+" │")
+	}
+
+	fmt.Fprintln(w, "")
+	rule("┌", "┐")
+	row(title, theme.Breadcrumbs.Paint(title))
+	rule("├", "┤")
+	for _, line := range body {
+		row(line.plain, line.painted)
+	}
+	if len(footer) > 0 {
+		rule("├", "┤")
+		for _, line := range footer {
+			row(line, theme.Footer.Paint(line))
+		}
+	}
+	rule("└", "┘")
+	fmt.Fprint(w, theme.Prompt.Paint(view.Prompt))
+	return nil
+}
+
+//CompactRenderer : the whole Menu on one line, for small menus
+type CompactRenderer struct{}
+
+//Render : see Renderer
+func (CompactRenderer) Render(w io.Writer, view *MenuView) error {
+	theme := view.Theme
+	parts := []string{theme.Breadcrumbs.Paint(view.header() + ":")}
+	for _, ev := range view.ordered() {
+		hint := ev.Hint
+		if ev.Checkable {
+			hint = checkMark(ev.Checked) + hint
+		}
+		part := "[" + theme.Key.Paint(ev.Key) + "] " + theme.hintStyle(ev).Paint(hint)
+		if ev.Badge != "" {
+			part = part + " (" + ev.Badge + ")"
+		}
+		if ev.Disabled {
+			part = part + " (unavailable)"
+		}
+		parts = append(parts, part)
+	}
+	if view.Default != "" {
+		parts = append(parts, fmt.Sprintf("<RET>=%s", view.Default))
+	}
+	fmt.Fprint(w, "\n"+strings.Join(parts, "  ")+"  "+theme.Prompt.Paint(view.Prompt))
+	return nil
+}
+
+//JSONRenderer : the MenuView as one line of JSON per display, for programs driving
+//the menus. No prompt is shown, the view has it.
+type JSONRenderer struct{}
+
+//Render : see Renderer
+func (JSONRenderer) Render(w io.Writer, view *MenuView) error {
+	line, err := json.Marshal(view)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(line))
+	return err
+}
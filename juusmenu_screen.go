@@ -0,0 +1,340 @@
+package juusmenu
+
+//Full screen mode: with MenuOptions.SetFullScreen(true) Menus are drawn in the
+//terminal's alternate screen instead of scrolling it. The breadcrumbs are shown
+//at the top, then the entries, with the selected one highlighted, the footer and
+//the prompt line. Below is the output pane, a scroll region where everything
+//the entry func()'s print goes, so they run, and prompt, as usual.
+//
+//  <Up> <Down> ^P ^N      select the previous, next entry
+//  <PgUp> <PgDn>          move a page of entries
+//  <Home> <End>           the first, last entry
+//  <RET>                  run the selected entry, or the Key typed
+//  <Esc> ^U               clear the Key typed
+//
+//Everything else typed at the prompt line works as in the classic mode.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+)
+
+const (
+	screenHelp     = "<Up>/<Down> select, <RET> runs it, or type a Key"
+	screenRule     = "------------------------------"
+	screenMinPane  = 5
+	screenDefCols  = 80
+	screenDefRows  = 24
+	ansiReverse    = "\x1b[7m"
+	ansiDim        = "\x1b[2m"
+	ansiReset      = "\x1b[0m"
+	ansiClearLine  = "\x1b[K"
+	ansiMoveTo     = "\x1b[%d;1H"
+	ansiSaveCursor = "\x1b7"
+	ansiRestore    = "\x1b8"
+)
+
+//fullScreenState : the state of full screen mode, shared by all Menus
+type fullScreenState struct {
+	active     bool
+	out        io.Writer
+	cols, rows int
+	//paneTop, promptRow : where the output pane starts, where the prompt line is
+	paneTop, promptRow int
+}
+
+//screen : the full screen, if screen.active
+var screen fullScreenState
+
+//screenLine : one line of the entry list, key is "" for section headers
+type screenLine struct {
+	text     string
+	key      string
+	disabled bool
+}
+
+//enterFullScreen : internal use. Switches to full screen mode if it is set and
+//possible, returns the func() that switches back. Nested Menus get a no-op.
+func enterFullScreen() (leave func()) {
+	if screen.active || !MenuOptions.fullScreen || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return func() {}
+	}
+	screen = fullScreenState{active: true, out: os.Stdout}
+	fmt.Fprint(screen.out, "\x1b[?1049h\x1b[2J")
+	return func() {
+		if screen.active {
+			leaveFullScreen()
+		}
+	}
+}
+
+//leaveFullScreen : internal use, resets the scroll region and goes back to the
+//normal screen
+func leaveFullScreen() {
+	fmt.Fprint(screen.out, "\x1b[r\x1b[?1049l")
+	screen = fullScreenState{}
+}
+
+//resize : internal use, reads the terminal size, keeping the last one known if it can't
+func (fs *fullScreenState) resize() {
+	if fs.out == io.Writer(os.Stdout) {
+		if cols, rows, err := terminalSize(os.Stdout); err == nil && cols > 0 && rows > 0 {
+			fs.cols, fs.rows = cols, rows
+		}
+	}
+	if fs.cols <= 0 || fs.rows <= 0 {
+		fs.cols, fs.rows = screenDefCols, screenDefRows
+	}
+}
+
+//drawScreen : internal use, displayMenu for full screen mode. The cursor is put
+//back in the output pane where it was, or at the top of the pane if it moved.
+func (menu *Menu) drawScreen() {
+	screen.resize()
+	view := menu.view()
+	lines := screenLines(view)
+	footer := view.footerLines()
+
+	listRows := screen.rows - 2 - len(footer) - 1 - screenMinPane
+	if listRows > len(lines) {
+		listRows = len(lines)
+	}
+	if listRows < 1 {
+		listRows = 1
+	}
+	paneTop := 2 + listRows + len(footer) + 2
+
+	var b bytes.Buffer
+	b.WriteString(ansiSaveCursor)
+	screenRow(&b, 1, ansiReverse+padRight(fitWidth(view.header(), screen.cols), screen.cols)+ansiReset)
+	screenRow(&b, 2, screenRule)
+	menu.writeList(&b, lines, listRows)
+	for i, line := range footer {
+		screenRow(&b, 3+listRows+i, fitWidth(line, screen.cols))
+	}
+	screen.promptRow = 3 + listRows + len(footer)
+	screenRow(&b, screen.promptRow, "")
+
+	//setting the scroll region homes the cursor
+	fmt.Fprintf(&b, "\x1b[%d;%dr", paneTop, screen.rows)
+	if paneTop != screen.paneTop {
+		for row := paneTop; row <= screen.rows; row++ {
+			screenRow(&b, row, "")
+		}
+		fmt.Fprintf(&b, ansiMoveTo, paneTop)
+		screen.paneTop = paneTop
+	} else {
+		b.WriteString(ansiRestore)
+	}
+	screen.out.Write(b.Bytes())
+}
+
+//writeList : internal use, writes rows lines of the entry list, scrolled so the
+//selected entry shows
+func (menu *Menu) writeList(b *bytes.Buffer, lines []screenLine, rows int) {
+	sel := menu.selectedLine(lines)
+	if sel < menu.listTop {
+		menu.listTop = sel
+	}
+	if sel >= menu.listTop+rows {
+		menu.listTop = sel - rows + 1
+	}
+	if menu.listTop > len(lines)-rows {
+		menu.listTop = len(lines) - rows
+	}
+	if menu.listTop < 0 {
+		menu.listTop = 0
+	}
+	for i := 0; i < rows; i++ {
+		text := ""
+		if n := menu.listTop + i; n < len(lines) {
+			line := lines[n]
+			text = fitWidth(line.text, screen.cols)
+			switch {
+			case n == sel:
+				text = ansiReverse + padRight(text, screen.cols) + ansiReset
+			case line.disabled:
+				text = ansiDim + text + ansiReset
+			}
+		}
+		screenRow(b, 3+i, text)
+	}
+}
+
+//screenLines : internal use, the entry list as full screen mode shows it
+func screenLines(view *MenuView) []screenLine {
+	keyWidth, _ := view.keyWidth()
+	var lines []screenLine
+	for _, ev := range view.ordered() {
+		if header := ev.sectionHeader(); header != "" {
+			lines = append(lines, screenLine{text: header})
+		}
+		text := padRight(ev.Key, keyWidth) + " :  " + ev.listHint()
+		if ev.Badge != "" {
+			text = text + "  " + fmt.Sprintf(badgeFormat, ev.Badge)
+		}
+		lines = append(lines, screenLine{text: text, key: ev.Key, disabled: ev.Disabled})
+	}
+	return lines
+}
+
+//selectedLine : internal use, the line of the selected entry, selecting the first
+//entry if the selected one is gone. -1 if there are no entries.
+func (menu *Menu) selectedLine(lines []screenLine) int {
+	first := -1
+	for i, line := range lines {
+		if line.key == "" {
+			continue
+		}
+		if line.key == menu.selectedKey {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		menu.selectedKey = lines[first].key
+	}
+	return first
+}
+
+//moveSelection : internal use, moves the selection by steps entries, down for
+//positive steps, stopping at the first and last entry
+func (menu *Menu) moveSelection(steps int) {
+	var keys []string
+	for _, line := range screenLines(menu.view()) {
+		if line.key != "" {
+			keys = append(keys, line.key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	cur := 0
+	for i, key := range keys {
+		if key == menu.selectedKey {
+			cur = i
+		}
+	}
+	cur += steps
+	if cur < 0 {
+		cur = 0
+	}
+	if cur >= len(keys) {
+		cur = len(keys) - 1
+	}
+	menu.selectedKey = keys[cur]
+}
+
+//navigate : internal use, readChoice for full screen mode. Returns the Key typed,
+//or that of the selected entry. ok is false if input ended.
+func (menu *Menu) navigate(in io.RuneReader) (input string, ok bool) {
+	fmt.Fprint(screen.out, ansiSaveCursor)
+	defer fmt.Fprint(screen.out, ansiRestore)
+
+	var typed []rune
+	defer whileWaiting(func() {
+		fmt.Fprint(screen.out, ansiRestore)
+		menu.drawScreen()
+		fmt.Fprint(screen.out, ansiSaveCursor)
+		menu.drawPrompt(string(typed))
+	})()
+	menu.drawPrompt("")
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return "", false
+		}
+		waiting.Lock()
+		input, ok, done := menu.navigateKey(in, r, &typed)
+		if !done {
+			menu.drawPrompt(string(typed))
+		}
+		waiting.Unlock()
+		if done {
+			return input, ok
+		}
+	}
+}
+
+//navigateKey : internal use, handles key r for navigate(), done is true when
+//navigate() should return input and ok
+func (menu *Menu) navigateKey(in io.RuneReader, r rune, typed *[]rune) (input string, ok, done bool) {
+	page := screen.rows - screenMinPane - 4
+	if page < 1 {
+		page = 1
+	}
+	switch {
+	case r == keyCtrlD && len(*typed) == 0:
+		return "", false, true
+	case r == keyCtrlC:
+		//the deferred leave never runs if the program stops
+		leaveFullScreen()
+		interrupt()
+		return "", false, true
+	case r == keyCR || r == keyLF:
+		if len(*typed) > 0 {
+			return string(*typed), true, true
+		}
+		return menu.selectedKey, true, true
+	case r == keyBackspace || r == keyCtrlH:
+		if len(*typed) > 0 {
+			*typed = (*typed)[:len(*typed)-1]
+		}
+	case r == keyCtrlU:
+		*typed = (*typed)[:0]
+	case r == keyCtrlP:
+		menu.moveSelection(-1)
+	case r == keyCtrlN:
+		menu.moveSelection(1)
+	case r == keyEscape:
+		final, params, _ := readEscape(in)
+		switch {
+		case params == "":
+			*typed = (*typed)[:0]
+		case final == 'A':
+			menu.moveSelection(-1)
+		case final == 'B':
+			menu.moveSelection(1)
+		case final == 'H' || params == "[1" || params == "[7":
+			menu.moveSelection(-len(menu.entries))
+		case final == 'F' || params == "[4" || params == "[8":
+			menu.moveSelection(len(menu.entries))
+		case params == "[5":
+			menu.moveSelection(-page)
+		case params == "[6":
+			menu.moveSelection(page)
+		}
+	case unicode.IsPrint(r):
+		*typed = append(*typed, r)
+		if menu.singleKey && menu.singleKeys() {
+			return string(*typed), true, true
+		}
+	}
+	return "", false, false
+}
+
+//drawPrompt : internal use, redraws the entry list and the prompt line with what
+//was typed so far, leaving the cursor on the prompt line
+func (menu *Menu) drawPrompt(typed string) {
+	var b bytes.Buffer
+	view := menu.view()
+	menu.writeList(&b, screenLines(view), screen.promptRow-3-len(view.footerLines()))
+	line := MenuOptions.menuPrompt + typed
+	if typed == "" {
+		line = line + ansiDim + fitWidth("  "+screenHelp, screen.cols-displayWidth(line)) + ansiReset
+	}
+	screenRow(&b, screen.promptRow, line)
+	fmt.Fprintf(&b, "\x1b[%d;%dH", screen.promptRow, displayWidth(MenuOptions.menuPrompt+typed)+1)
+	screen.out.Write(b.Bytes())
+}
+
+//screenRow : internal use, writes text on a row of the screen, clearing the rest of it
+func screenRow(b *bytes.Buffer, row int, text string) {
+	fmt.Fprintf(b, ansiMoveTo, row)
+	b.WriteString(text + ansiClearLine)
+}
@@ -0,0 +1,129 @@
+package juusmenu
+
+//Template layouts: a TemplateRenderer lays out Menus with a text/template, so a
+//layout can be changed without writing Go. The template is executed with the
+//*MenuView of the Menu, see MenuView, and these functions:
+//
+//  crumbs .           the breadcrumbs line, with the page shown of a paged Menu
+//  entries .          the entries with the Break Item in its place
+//  keyWidth .         the width of the widest Key
+//  footer .           the lines the classic layout shows below the entries
+//  header .           for an entry, the section header shown above it, or ""
+//  hint .             for an entry, the hint with check mark and why it is unavailable
+//  pad n s            s padded with spaces on the right to n columns
+//  padLeft n s        s padded with spaces on the left to n columns
+//  width s            the number of terminal columns s takes
+//  repeat n s         s repeated n times
+//  join list sep      the strings of list joined by sep
+//  upper s, lower s   s in upper, lower case
+//  color name s       s in an ANSI colour or style: black red green yellow blue
+//                     magenta cyan white bold dim underline reverse, if
+//                     colours are allowed, see MenuOptions.SetColorMode()
+//  style element s    s in the Style of a theme element, like "key", see
+//                     juusmenu_theme.go, if colours are on
+//
+//ClassicTemplate, close to the classic layout, is a starting point.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+)
+
+//ClassicTemplate : close to the classic layout, as a template for a TemplateRenderer
+const ClassicTemplate = `
+{{crumbs .}}
+------------------------------
+{{$w := keyWidth .}}{{range entries .}}{{with header .}}{{.}}
+{{end}}{{pad $w .Key}} :  {{hint .}}{{with .Badge}} [{{.}}]{{end}}
+{{end}}{{range footer .}}{{.}}
+{{end}}{{.Prompt}}`
+
+//ansiStyles : the names the color template function knows
+var ansiStyles = map[string]string{
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+	"bold": "1", "dim": "2", "underline": "4", "reverse": "7",
+}
+
+//TemplateRenderer : a Renderer executing a text/template, see NewTemplateRenderer()
+type TemplateRenderer struct {
+	tmpl *template.Template
+	//err : the error of a failed execution, the classic layout is used from then on
+	err error
+}
+
+//NewTemplateRenderer : Returns a Renderer laying out Menus with the text/template
+//text. Use it for all Menus with MenuOptions.SetRenderer() or for one with
+//Menu.SetRenderer(). If the template fails when a Menu is shown the user is
+//alerted once and the classic layout is used instead.
+func NewTemplateRenderer(text string) (*TemplateRenderer, error) {
+	tmpl, err := template.New("menu").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		errmsg := warn + fmt.Sprintf("NewTemplateRenderer: the template does not parse: %s", err)
+		alertUser(&errmsg)
+		return nil, errors.New(errmsg)
+	}
+	return &TemplateRenderer{tmpl: tmpl}, nil
+}
+
+//LoadTemplateRenderer : Same as NewTemplateRenderer, with the template read from file path.
+func LoadTemplateRenderer(path string) (*TemplateRenderer, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		errmsg := warn + fmt.Sprintf("LoadTemplateRenderer: can not read template file '%s': %s", path, err)
+		alertUser(&errmsg)
+		return nil, errors.New(errmsg)
+	}
+	return NewTemplateRenderer(string(text))
+}
+
+//Render : see Renderer
+func (tr *TemplateRenderer) Render(w io.Writer, view *MenuView) error {
+	if tr.err != nil {
+		return ClassicRenderer{}.Render(w, view)
+	}
+	if err := tr.tmpl.Execute(w, view); err != nil {
+		tr.err = err
+		return err
+	}
+	return nil
+}
+
+//templateFuncs : the functions templates can use, see the top of this file
+var templateFuncs = template.FuncMap{
+	"crumbs":   func(view *MenuView) string { return view.header() },
+	"entries":  func(view *MenuView) []EntryView { return view.ordered() },
+	"keyWidth": func(view *MenuView) int { w, _ := view.keyWidth(); return w },
+	"footer":   func(view *MenuView) []string { return view.footerLines() },
+	"header":   func(ev EntryView) string { return ev.sectionHeader() },
+	"hint":     func(ev EntryView) string { return ev.listHint() },
+	"pad":      func(n int, s string) string { return padRight(s, n) },
+	"padLeft":  func(n int, s string) string { return padLeft(s, n) },
+	"width":    displayWidth,
+	"repeat":   func(n int, s string) string { return strings.Repeat(s, n) },
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"style": func(element, s string) (string, error) {
+		theme := MenuOptions.theme
+		style := theme.element(element)
+		if style == nil {
+			return "", fmt.Errorf("unknown theme element '%s'", element)
+		}
+		return style.Paint(s), nil
+	},
+	"color": func(name, s string) (string, error) {
+		code, ok := ansiStyles[name]
+		if !ok {
+			return "", fmt.Errorf("unknown color '%s'", name)
+		}
+		if !colorAllowed() {
+			return s, nil
+		}
+		return "\x1b[" + code + "m" + s + ansiReset, nil
+	},
+}
@@ -0,0 +1,12 @@
+//go:build linux
+// +build linux
+
+package juusmenu
+
+import "syscall"
+
+//the termios get/set ioctl requests of linux
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
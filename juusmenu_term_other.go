@@ -0,0 +1,43 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package juusmenu
+
+//Terminal handling for systems without termios, like windows: the terminal is
+//never put in raw mode, so input is always read line by line, and output is
+//never taken for a terminal. The line editor, single key Menus, full screen mode,
+//the pager and ColorAuto colours are not available there.
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+//isTerminal : internal use, always false here
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+//makeRaw : internal use, raw mode is not supported here
+func makeRaw(f *os.File) (restore func(), err error) {
+	return nil, errors.New("raw terminal mode is not supported on this system")
+}
+
+//readTimeout : internal use, raw mode is not supported here
+func readTimeout(f *os.File, timeout time.Duration) error {
+	return errors.New("raw terminal mode is not supported on this system")
+}
+
+//interrupt : internal use, stops the program as ^C would
+func interrupt() {
+	os.Exit(130)
+}
+
+//terminalSize : internal use, not known here
+func terminalSize(f *os.File) (cols, rows int, err error) {
+	return 0, 0, errors.New("terminal size is not known on this system")
+}
+
+//watchResize : internal use, resizing is not noticed here
+func watchResize(resized func()) {}
@@ -0,0 +1,116 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package juusmenu
+
+//Terminal handling for linux, macOS and the BSDs, through the termios ioctls,
+//see juusmenu_term_linux.go and juusmenu_term_bsd.go for their requests. Other
+//systems use juusmenu_term_other.go, where the terminal is never put in raw mode.
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+//ioctl : internal use, the termios get/set calls
+func ioctl(fd uintptr, request uintptr, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+//isTerminal : internal use, true if f is a terminal
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	return ioctl(f.Fd(), ioctlGetTermios, &termios) == nil
+}
+
+//makeRaw : internal use, puts terminal f in raw mode: no echo, no line buffering,
+//no signals from ^C. Output processing is kept, so "\n" still starts a new line.
+//restore puts the terminal back as it was.
+func makeRaw(f *os.File) (restore func(), err error) {
+	var old syscall.Termios
+	if err = ioctl(f.Fd(), ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+	outer := cooked == nil
+	if outer {
+		saved := old
+		cooked, cookedFd = &saved, f.Fd()
+	}
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err = ioctl(f.Fd(), ioctlSetTermios, &raw); err != nil {
+		if outer {
+			cooked = nil
+		}
+		return nil, err
+	}
+	return func() {
+		ioctl(f.Fd(), ioctlSetTermios, &old)
+		if outer {
+			cooked = nil
+		}
+	}, nil
+}
+
+//cooked : the terminal settings before the outermost makeRaw, nil if the terminal
+//is not raw. interrupt() puts them back, the deferred restores never run.
+var (
+	cooked   *syscall.Termios
+	cookedFd uintptr
+)
+
+//readTimeout : internal use, makes reads of raw terminal f give up with io.EOF
+//when nothing is typed within timeout, up to 25.5s. 0 waits for a key again.
+func readTimeout(f *os.File, timeout time.Duration) error {
+	var termios syscall.Termios
+	if err := ioctl(f.Fd(), ioctlGetTermios, &termios); err != nil {
+		return err
+	}
+	termios.Cc[syscall.VMIN], termios.Cc[syscall.VTIME] = 1, 0
+	if timeout > 0 {
+		tenths := (timeout + 99*time.Millisecond) / (100 * time.Millisecond)
+		if tenths > 255 {
+			tenths = 255
+		}
+		termios.Cc[syscall.VMIN], termios.Cc[syscall.VTIME] = 0, uint8(tenths)
+	}
+	return ioctl(f.Fd(), ioctlSetTermios, &termios)
+}
+
+//interrupt : internal use, sends ourselves the ^C the raw terminal swallowed,
+//after putting the terminal back as it was before makeRaw
+func interrupt() {
+	if cooked != nil {
+		ioctl(cookedFd, ioctlSetTermios, cooked)
+	}
+	syscall.Kill(os.Getpid(), syscall.SIGINT)
+}
+
+//terminalSize : internal use, the columns and rows of terminal f
+func terminalSize(f *os.File) (cols, rows int, err error) {
+	var ws struct{ rows, cols, xpixel, ypixel uint16 }
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, 0, errno
+	}
+	return int(ws.cols), int(ws.rows), nil
+}
+
+//watchResize : internal use, calls resized every time the terminal is resized
+func watchResize(resized func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		for range ch {
+			resized()
+		}
+	}()
+}
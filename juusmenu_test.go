@@ -4,12 +4,25 @@ package juusmenu
 //I need to study how to test a unit that requires user input.
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"text/tabwriter"
+	"time"
 )
 
 func TestUnInitializedMenu(t *testing.T) {
-	MenuOptions.SetRunTimeErrMsgs_Display(false)
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
 	tmpMenu := NewMenu("NewMenu")
 	err := tmpMenu.Start()
 	if err == nil {
@@ -21,7 +34,7 @@ func TestDuplicateIDs(t *testing.T) {
 	//todo ok so here is a good example of what to do about different errors.
 	//SetID will return an error FIRST if the menu.isRunning=true.
 	//so this test will give a false positive if tmpMenu2 were running...
-	MenuOptions.SetRunTimeErrMsgs_Display(false)
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
 	tmpMenu, tmpMenu2 := NewMenu("TmpMenu"), NewMenu("TmpMenu2")
 	tmpMenu.SetID(42)
 	err := tmpMenu2.SetID(42)
@@ -34,7 +47,7 @@ func TestAddSubMenuStub(t *testing.T) {
 	//todo so that func has lots of validations, so I would need a test
 	//for each validation that I make, and other random tests that test things
 	//I didn't think of. Need to think about an efficient way to do this.
-	MenuOptions.SetRunTimeErrMsgs_Display(false)
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
 	tmpMenu, tmpMenu2 := NewMenu("TmpMenu"), NewMenu("TmpMenu2")
 	tmpMenu.SetMenuBreakItem("q", "Quit", func() { fmt.Println("byebye") })
 	tmpMenu2.SetMenuBreakItem("b", "b", func() { fmt.Println("back") })
@@ -52,7 +65,7 @@ func TestManyMenus(t *testing.T) {
 	menuNum := 0
 	menus := make([]*Menu, 0, 3)
 	var tmpMenu *Menu
-	MenuOptions.SetRunTimeErrMsgs_Display(false)
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
 
 	for i := 0; i < 3; i++ {
 		menuNum++
@@ -66,3 +79,1159 @@ func TestManyMenus(t *testing.T) {
 		menus[i].Start()
 	}
 }
+
+//setInput : replaces the menu system's input with the lines in input
+//so that menus can be "typed" at during tests.
+func setInput(input string) {
+	menuScanner = bufio.NewScanner(strings.NewReader(input))
+	pendingScan = nil
+}
+
+func TestSuggestKeys(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpSuggest")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("list", "list things", func() {})
+	tmpMenu.AddMenuEntry("load", "load things", func() {})
+	tmpMenu.AddMenuEntry("x", "x marks the spot", func() {})
+	tmpMenu.finalize()
+
+	if got := tmpMenu.suggestKeys("lsit"); len(got) == 0 || got[0] != "list" {
+		t.Errorf("Failed: 'lsit' should suggest 'list' first, got %v", got)
+	}
+	if got := tmpMenu.suggestKeys("Q"); len(got) != 1 || got[0] != "q" {
+		t.Errorf("Failed: 'Q' should suggest only 'q', got %v", got)
+	}
+	if got := tmpMenu.suggestKeys("y"); len(got) != 0 {
+		t.Errorf("Failed: 'y' should not suggest anything, got %v", got)
+	}
+}
+
+func TestSuggestRun(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	MenuOptions.SetPauseOnOutput(false)
+	MenuOptions.SetSuggestRun(true)
+	defer MenuOptions.SetPauseOnOutput(defpauseOnOutput)
+	defer MenuOptions.SetSuggestRun(defsuggestRun)
+
+	ran := false
+	tmpMenu := NewMenu("TmpSuggestRun")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("list", "list things", func() { ran = true })
+	setInput("lsit\ny\nq\n")
+	if err := tmpMenu.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("Failed: confirmed suggestion 'list' should have run.")
+	}
+}
+
+func TestAutoKeys(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpAutoKeys")
+	tmpMenu.SetAutoKeys(AutoKeyNumeric, true)
+	tmpMenu.SetMenuBreakItem("2", "Quit", func() {})
+	tmpMenu.AddMenuEntry("zebra", "added first", func() {})
+	tmpMenu.AddMenuEntry("apple", "added second", func() {})
+	tmpMenu.AddMenuEntry("mango", "added third", func() {})
+
+	//"2" is the Break Value and must be skipped
+	for id, want := range map[string]string{"zebra": "1", "apple": "3", "mango": "4"} {
+		if got, _ := tmpMenu.EntryKey(id); got != want {
+			t.Errorf("Failed: ID '%s' should have Key '%s', got '%s'", id, want, got)
+		}
+	}
+
+	tmpMenu.RemoveMenuEntry("zebra")
+	if got, _ := tmpMenu.EntryKey("mango"); got != "3" {
+		t.Errorf("Failed: after removal 'mango' should be renumbered to '3', got '%s'", got)
+	}
+
+	if got := autoKey(27, []rune(alphaKeyAlphabet)); got != "aa" {
+		t.Errorf("Failed: 27th alpha key should be 'aa', got '%s'", got)
+	}
+
+	if got := AutoKeyStyle(9).String(); got != "AutoKeyStyle(9)" {
+		t.Errorf("Failed: an invalid AutoKeyStyle should print as 'AutoKeyStyle(9)', got '%s'", got)
+	}
+}
+
+func TestSortOrders(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpSortOrders")
+	tmpMenu.SetMenuBreakItem("0", "Quit", func() {})
+	for _, k := range []string{"10", "2", "item10", "item9", "1"} {
+		tmpMenu.AddMenuEntry(k, "hint "+k, func() {})
+	}
+	check := func(want string) {
+		t.Helper()
+		tmpMenu.EntryKey("1")
+		if got := strings.Join(tmpMenu.sortKeys, ","); got != want {
+			t.Errorf("Failed: order should be '%s', got '%s'", want, got)
+		}
+	}
+
+	tmpMenu.SetSortOrder(OrderNatural)
+	check("1,2,10,item9,item10," + breakIndicator)
+
+	tmpMenu.SetSortOrder(OrderWeight)
+	tmpMenu.SetEntryWeight("item9", -1)
+	tmpMenu.SortDescending()
+	check(breakIndicator + ",item10,2,10,1,item9")
+
+	tmpMenu.SortAscending()
+	tmpMenu.SetSortOrder(OrderInsertion)
+	check("10,2,item10,item9,1," + breakIndicator)
+}
+
+func TestSections(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpSections")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddSection("b", "Section B")
+	tmpMenu.AddSection("a", "Section A")
+	tmpMenu.AddSectionEntry("a", "3", "in a", func() {})
+	tmpMenu.AddSectionEntry("b", "2", "in b", func() {})
+	tmpMenu.AddSectionEntry("a", "1", "in a", func() {})
+	tmpMenu.AddMenuEntry("9", "no section", func() {})
+	if err := tmpMenu.AddSectionEntry("nope", "4", "no such section", func() {}); err == nil {
+		t.Error("Failed: adding to a section that does not exist should return an error.")
+	}
+
+	tmpMenu.finalize()
+	want := "9,2,1,3," + breakIndicator
+	if got := strings.Join(tmpMenu.sortKeys, ","); got != want {
+		t.Errorf("Failed: order should be '%s', got '%s'", want, got)
+	}
+
+	tmpMenu.RemoveSection("b")
+	tmpMenu.reSet()
+	want = "2,9,1,3," + breakIndicator
+	if got := strings.Join(tmpMenu.sortKeys, ","); got != want {
+		t.Errorf("Failed: after RemoveSection order should be '%s', got '%s'", want, got)
+	}
+}
+
+func TestDisabledAndHiddenEntries(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	MenuOptions.SetPauseOnOutput(false)
+	defer MenuOptions.SetPauseOnOutput(defpauseOnOutput)
+
+	loggedIn, ranDisabled, ranHidden := false, false, false
+	tmpMenu := NewMenu("TmpPredicates")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("d", "disabled", func() { ranDisabled = true })
+	tmpMenu.AddMenuEntry("h", "hidden", func() { ranHidden = true })
+	tmpMenu.AddMenuEntry("l", "log in", func() { loggedIn = true })
+	tmpMenu.SetEntryEnabled("d", func() bool { return loggedIn }, "requires login")
+	tmpMenu.SetEntryVisible("h", func() bool { return loggedIn })
+	if err := tmpMenu.SetEntryVisible("q", func() bool { return false }); err == nil {
+		t.Error("Failed: the break item must not be hideable.")
+	}
+
+	setInput("d\nh\nq\n")
+	tmpMenu.Start()
+	if ranDisabled || ranHidden {
+		t.Error("Failed: disabled and hidden entries must not run.")
+	}
+
+	//predicates are evaluated at display, so logging in enables both
+	setInput("l\nd\nh\nq\n")
+	tmpMenu.Start()
+	if !ranDisabled || !ranHidden {
+		t.Error("Failed: enabled and visible entries should run.")
+	}
+}
+
+func TestRenderTimeFuncs(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	user, items, badge := "ann", 3, "new"
+	tmpMenu := NewMenu("TmpLive")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("c", "cache", func() {})
+	tmpMenu.AddMenuEntry("m", "mail", func() {})
+	tmpMenu.SetTitleFunc(func() string { return "Logged in as " + user })
+	tmpMenu.SetEntryHintFunc("c", func() string { return fmt.Sprintf("Cache: %d items", items) })
+	tmpMenu.SetEntryBadge("m", func() string { return badge })
+	if err := tmpMenu.SetEntryBadge("x", func() string { return "" }); err == nil {
+		t.Error("Failed: a badge for a Key that does not exist should be refused.")
+	}
+	tmpMenu.finalize()
+	sortKeys := strings.Join(tmpMenu.sortKeys, ",")
+
+	output := captureOutput(tmpMenu.displayMenu)
+	for _, want := range []string{"Logged in as ann", "Cache: 3 items", "[new]"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Failed: '%s' should be shown, got:\n%s", want, output)
+		}
+	}
+
+	//new results show at the next display, the Menu is not finalized again
+	user, items, badge = "bob", 7, ""
+	output = captureOutput(tmpMenu.displayMenu)
+	for _, want := range []string{"Logged in as bob", "Cache: 7 items"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Failed: '%s' should be shown, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "[new]") || strings.Contains(output, "ann") {
+		t.Errorf("Failed: the old title and badge should be gone, got:\n%s", output)
+	}
+	if tmpMenu.isModified || strings.Join(tmpMenu.sortKeys, ",") != sortKeys {
+		t.Error("Failed: render time funcs should not modify or re-sort the Menu.")
+	}
+}
+
+func TestStateEntries(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	verbose, format, retries := false, "json", 3
+	changes := 0
+	tmpMenu := NewMenu("TmpStateEntries")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddToggle("v", "Verbose", &verbose, func(bool) { changes++ })
+	tmpMenu.AddCycle("f", "Format", &format, []string{"json", "text", "csv"}, nil)
+	tmpMenu.AddCounter("r", "Retries", &retries, 0, 5, 1, func(int) { changes++ })
+	if err := tmpMenu.AddCounter("x", "Bad", &retries, 5, 0, 1, nil); err == nil {
+		t.Error("Failed: a counter with min > max should not be added.")
+	}
+
+	//no pause is expected after state entries, so no blank lines in the input
+	setInput("v\nf\nf\nf\nf\nr\n+\nr\n9\nq\n")
+	tmpMenu.Start()
+	if !verbose || format != "text" || retries != 4 || changes != 2 {
+		t.Errorf("Failed: got verbose=%v format=%s retries=%d changes=%d", verbose, format, retries, changes)
+	}
+	if got := tmpMenu.entries["v"].displayHint(); got != toggleOn+"Verbose" {
+		t.Errorf("Failed: toggle should display '%s', got '%s'", toggleOn+"Verbose", got)
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpChecklist")
+	tmpMenu.SetMenuBreakItem("c", "Cancel", func() {})
+	tmpMenu.SetChecklist("ok", "restart selected", 1, 2)
+	tmpMenu.AddChecklistItem("1", "web", "nginx")
+	tmpMenu.AddChecklistItem("2", "db", "postgres")
+	tmpMenu.AddChecklistItem("3", "cache", "redis")
+
+	//'ok' with nothing selected and '*' over the max pause, hence the blank lines
+	setInput("ok\n\n*\n\n1\n3\n2\n\n~\n-\n2\n3\nok\n")
+	got, err := tmpMenu.StartChecklist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "2,3" {
+		t.Errorf("Failed: selection should be '2,3', got %v", got)
+	}
+	if vals := tmpMenu.CheckedValues(); len(vals) != 2 || vals[0] != "postgres" {
+		t.Errorf("Failed: values should be [postgres redis], got %v", vals)
+	}
+
+	setInput("1\nc\n")
+	if got, _ = tmpMenu.StartChecklist(); got != nil {
+		t.Errorf("Failed: canceled checklist should return nil, got %v", got)
+	}
+	if strings.Join(tmpMenu.Checked(), ",") != "2,3" {
+		t.Errorf("Failed: canceling should restore the marks, got %v", tmpMenu.Checked())
+	}
+
+	tmpMenu.AddChecklistItem("*", "all", "everything")
+	report, _ := tmpMenu.doValidate()
+	if !strings.Contains(report, "Key '*' which conflicts with a checklist command") {
+		t.Errorf("Failed: a Key taken by a checklist command should be reported, got '%s'", report)
+	}
+
+	//auto keys skip the confirm key 'd', the 4th item gets 'e' and can be marked
+	lettered := NewMenu("TmpChecklistAuto")
+	lettered.SetMenuBreakItem("q", "Cancel", func() {})
+	lettered.SetAutoKeys(AutoKeyAlpha, true)
+	lettered.SetChecklist("d", "done", 0, 0)
+	for i := 1; i <= 5; i++ {
+		lettered.AddChecklistItem(fmt.Sprintf("id%d", i), fmt.Sprintf("item %d", i), i)
+	}
+	setInput("e\nd\n")
+	got, _ = lettered.StartChecklist()
+	if key := lettered.entries["id4"].value; key != "e" || strings.Join(got, ",") != "id4" {
+		t.Errorf("Failed: the 4th item should have Key 'e' and be selected, got '%s' and %v", key, got)
+	}
+}
+
+func TestRadioGroup(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	output, other := "", ""
+	var changedTo []string
+	tmpMenu := NewMenu("TmpRadio")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddRadio("output", "j", "json", &output, "json")
+	tmpMenu.AddRadio("output", "t", "text", &output, "text")
+	tmpMenu.AddRadio("output", "c", "csv", &output, "csv")
+	tmpMenu.SetRadioOnChange("output", func(v string) { changedTo = append(changedTo, v) })
+	if err := tmpMenu.AddRadio("output", "x", "xml", &other, "xml"); err == nil {
+		t.Error("Failed: a radio group must be bound to a single variable.")
+	}
+	//a radio that can't be added leaves no group behind
+	if err := tmpMenu.AddRadio("level", "", "low", &other, "low"); err == nil {
+		t.Error("Failed: a radio with an empty Key should be refused.")
+	}
+	if _, ok := tmpMenu.radioGroups["level"]; ok {
+		t.Error("Failed: a refused radio should not create its group.")
+	}
+
+	//"" is not a group value, so the first entry in display order is selected
+	setInput("t\nt\nj\nq\n")
+	tmpMenu.Start()
+	if output != "json" || strings.Join(changedTo, ",") != "text,json" {
+		t.Errorf("Failed: output should be 'json' after changes 'text,json', got '%s' after %v", output, changedTo)
+	}
+	if tmpMenu.entries["c"].displayHint() != radioOff+"csv" || tmpMenu.entries["j"].displayHint() != radioOn+"json" {
+		t.Error("Failed: only the selected radio entry should be marked.")
+	}
+}
+
+func TestForm(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	var result FormValues
+	form := NewForm("TmpForm", func(v FormValues) { result = v })
+	form.AddField("name", "Name", FieldText, true)
+	form.AddField("age", "Age", FieldInt, false)
+	form.SetFieldValidator("age", func(v string) error {
+		if n, _ := strconv.Atoi(v); n < 0 {
+			return errors.New("can not be negative")
+		}
+		return nil
+	})
+
+	//submit is refused without the required name, 'abc' and '-1' are rejected
+	setInput("s\n2\nabc\n\n2\n-1\n\n1\nAnn\n2\n42\ns\n")
+	submitted, err := form.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !submitted || result["name"] != "Ann" || result["age"] != "42" {
+		t.Errorf("Failed: form should be submitted with Ann, 42, got %v %v", submitted, result)
+	}
+	if age, _ := result.Int("age"); age != 42 {
+		t.Errorf("Failed: age should be 42, got %d", age)
+	}
+
+	setInput("1\nBob\n2\n-\nc\n")
+	if submitted, _ = form.Start(); submitted {
+		t.Error("Failed: a canceled form should not be submitted.")
+	}
+	if v := form.Values(); v["name"] != "Ann" || v["age"] != "42" {
+		t.Errorf("Failed: canceling should discard the changes, got %v", v)
+	}
+
+	form.SetFieldValue("name", "")
+	if err := form.Validate(); err == nil || !strings.Contains(err.Error(), "'Name' is required") {
+		t.Errorf("Failed: an empty required field should not validate, got %v", err)
+	}
+
+	//values are submitted normalized, a "yes" default as "true"
+	form.AddField("news", "Newsletter", FieldBool, false)
+	form.SetFieldValue("name", "Ann")
+	form.SetFieldValue("news", "yes")
+	setInput("s\n")
+	form.Start()
+	if news, err := result.Bool("news"); err != nil || !news {
+		t.Errorf("Failed: 'yes' should be submitted as true, got '%s'", result["news"])
+	}
+
+	//a Form created first does not become the MAIN MENU, the next Menu does
+	saved := allMenus
+	allMenus = nil
+	first := NewForm("TmpFormFirst", nil)
+	main := NewMenu("TmpMainAfterForm")
+	allMenus = saved
+	if first.menu.isMainMenu || !main.isMainMenu {
+		t.Error("Failed: the first Menu after a Form should be the MAIN MENU.")
+	}
+}
+
+func TestWizard(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	var result WizardAnswers
+	wiz := NewWizard("TmpWizard", func(a WizardAnswers) { result = a })
+
+	kind := NewMenu("TmpWizardKind")
+	kind.SetMenuBreakItem("c", "Cancel", func() {})
+	kind.AddMenuEntry("p", "Personal", func() {})
+	kind.AddMenuEntry("b", "Business", func() {})
+
+	wiz.AddPromptStep("name", "Your name", nil)
+	wiz.AddChoiceStep("kind", kind)
+	wiz.AddPromptStep("company", "Company name", nil)
+	wiz.AddPromptStep("email", "E-mail", func(v string) error {
+		if !strings.Contains(v, "@") {
+			return fmt.Errorf("no @")
+		}
+		return nil
+	})
+	//personal accounts skip the company step
+	wiz.SetStepNext("kind", func(a WizardAnswers) string {
+		if a["kind"] == "p" {
+			return "email"
+		}
+		return ""
+	})
+
+	//name, business, company, then back twice to choose personal instead,
+	//a rejected and an accepted e-mail, then back from the summary and
+	//keep the e-mail with <RET>, then finish
+	setInput("Ada\nb\nACME\n<\n<\np\nada\nada@x\n<\n\ny\n")
+	completed, err := wiz.Start()
+	if err != nil || !completed {
+		t.Fatalf("Failed: wizard should have completed, err: %v", err)
+	}
+	if result["kind"] != "p" || result["email"] != "ada@x" || result["name"] != "Ada" {
+		t.Errorf("Failed: unexpected answers %v", result)
+	}
+	if _, ok := result["company"]; ok {
+		t.Errorf("Failed: the skipped company step should not be in the answers %v", result)
+	}
+
+	//an auto keyed step keeps the '<' Key, a wizard without SetStepNext counts its steps
+	plan := NewMenu("TmpWizardPlan")
+	plan.SetMenuBreakItem("c", "Cancel", func() {})
+	progress, backKey := "", ""
+	plan.AddMenuEntry("free", "Free", func() { progress, backKey = plan.crumbPrefix, plan.entries[wizardBackKey].value })
+	plan.AddMenuEntry("paid", "Paid", func() {})
+	plan.SetAutoKeys(AutoKeyNumeric, true)
+	linear := NewWizard("TmpLinear", nil)
+	linear.AddPromptStep("name", "Your name", nil)
+	linear.AddChoiceStep("plan", plan)
+	setInput("Ada\n1\ny\n")
+	if completed, _ := linear.Start(); !completed || !strings.HasSuffix(progress, "Step 2/2") || backKey != wizardBackKey {
+		t.Errorf("Failed: linear wizard should complete showing 'Step 2/2' and '<', got %v '%s' '%s'", completed, progress, backKey)
+	}
+	if wiz.stepsLeft(0) != 0 || linear.stepsLeft(1) != 1 {
+		t.Error("Failed: only wizards without SetStepNext after the step should count the steps left.")
+	}
+}
+
+func TestEntryConfirm(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	MenuOptions.SetPauseOnOutput(false)
+	defer MenuOptions.SetPauseOnOutput(defpauseOnOutput)
+
+	deleted, wiped := 0, 0
+	tmpMenu := NewMenu("TmpConfirm")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("d", "delete", func() { deleted++ })
+	tmpMenu.AddMenuEntry("wipe", "wipe everything", func() { wiped++ })
+	tmpMenu.SetEntryConfirm("d", ConfirmYesNo, "")
+	tmpMenu.SetEntryConfirm("wipe", ConfirmRetypeKey, "This can not be undone!")
+
+	setInput("d\nn\nd\ny\nwipe\ny\nwipe\nwipe\nq\n")
+	tmpMenu.Start()
+	if deleted != 1 || wiped != 1 {
+		t.Errorf("Failed: expected 1 delete and 1 wipe, got %d and %d", deleted, wiped)
+	}
+
+	MenuOptions.SetConfirmBypass(true)
+	defer MenuOptions.SetConfirmBypass(defconfirmBypass)
+	setInput("wipe\nq\n")
+	tmpMenu.Start()
+	if wiped != 2 {
+		t.Error("Failed: confirmBypass should run the entry without asking.")
+	}
+}
+
+func TestDefaultChoice(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	chosen := ""
+	tmpMenu := NewMenu("TmpDefaultChoice")
+	tmpMenu.SetChooseOne(true)
+	tmpMenu.SetMenuBreakItem("c", "Cancel", func() { chosen = "cancel" })
+	tmpMenu.AddMenuEntry("y", "yes", func() { chosen = "yes" })
+	tmpMenu.AddMenuEntry("n", "no", func() { chosen = "no" })
+	tmpMenu.SetDefaultChoice("n", 0)
+
+	setInput("\n")
+	tmpMenu.Start()
+	if chosen != "no" {
+		t.Errorf("Failed: <RET> should choose the default 'n', got '%s'", chosen)
+	}
+
+	//nobody types anything, the default is taken after the timeout
+	reader, writer := io.Pipe()
+	menuScanner, pendingScan = bufio.NewScanner(reader), nil
+	tmpMenu.SetDefaultChoice("c", 50*time.Millisecond)
+	tmpMenu.Start()
+	if chosen != "cancel" {
+		t.Errorf("Failed: timeout should choose the default 'c', got '%s'", chosen)
+	}
+	//the scan that gave up still waits, end it before the next test
+	writer.Close()
+	if _, ok := readLine(); ok || pendingScan != nil {
+		t.Error("Failed: the next read should take the result of the pending scan.")
+	}
+}
+
+func TestBlankInput(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	MenuOptions.SetPauseOnOutput(false)
+	defer MenuOptions.SetPauseOnOutput(defpauseOnOutput)
+
+	refreshed, other := 0, 0
+	tmpMenu := NewMenu("TmpBlankInput")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("r", "refresh status", func() { refreshed++ })
+	tmpMenu.AddMenuEntry("o", "other", func() { other++ })
+
+	tmpMenu.SetBlankInput(BlankRepeat)
+	setInput("\nr\n\n\no\n\nq\n")
+	tmpMenu.Start()
+	if refreshed != 3 || other != 2 {
+		t.Errorf("Failed: expected 3 refreshes and 2 others, got %d and %d", refreshed, other)
+	}
+
+	tmpMenu.SetBlankInput(BlankDefault)
+	tmpMenu.SetDefaultChoice("r", 0)
+	setInput("\nq\n")
+	tmpMenu.Start()
+	if refreshed != 4 {
+		t.Errorf("Failed: blank input should run the default entry, got %d refreshes", refreshed)
+	}
+}
+
+func TestInputHistory(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	MenuOptions.SetPauseOnOutput(false)
+	defer MenuOptions.SetPauseOnOutput(defpauseOnOutput)
+	dir, err := ioutil.TempDir("", "juusmenu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history")
+	defer MenuOptions.SetHistoryFile("", defhistoryLimit)
+	MenuOptions.SetHistoryFile(path, 3)
+
+	a, b := 0, 0
+	tmpMenu := NewMenu("TmpHistory")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "a", func() { a++ })
+	tmpMenu.AddMenuEntry("b", "b", func() { b++ })
+
+	setInput("a\nb\n!!\n!1\n!h\n!9\nq\n")
+	tmpMenu.Start()
+	if a != 2 || b != 2 {
+		t.Errorf("Failed: expected recalls to run a and b twice, got %d and %d", a, b)
+	}
+
+	setInput("typed\n!!\n")
+	if GetUserInput("Name") != "typed" || GetUserInput("Name") != "typed" {
+		t.Error("Failed: GetUserInput should recall its own history.")
+	}
+	setInput("hidden\n")
+	GetSecretInput("Password")
+	if len(promptHistory(promptHistoryID("Password"))) != 0 {
+		t.Error("Failed: secret input should not be kept in the history.")
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("Failed: history file should keep 3 inputs, has %d", lines)
+	}
+	inputHistory = nil
+	MenuOptions.SetHistoryFile(path, 3)
+	if got := promptHistory(promptHistoryID("Name")); len(got) != 2 || got[1] != "typed" {
+		t.Errorf("Failed: history should be loaded from file, got %v", got)
+	}
+
+	//a Key starting with '!' is run, the kill phrase is not kept
+	bang := 0
+	tmpMenu.AddMenuEntry("!x", "bang", func() { bang++ })
+	setInput("!x\n" + MenuOptions.killPhrase + "\n")
+	tmpMenu.Start()
+	killSwitch = false
+	if got := promptHistory(tmpMenu.historyID()); bang != 1 || got[len(got)-1] != "!x" {
+		t.Errorf("Failed: Key '!x' should run and be the last input kept, got %d runs and %v", bang, got)
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	edit := func(ed *lineEditor, keys string) string {
+		line, _ := ed.edit(strings.NewReader(keys), ioutil.Discard)
+		return line
+	}
+	tests := []struct{ keys, want string }{
+		{"abc\r", "abc"},
+		{"ac\x1b[Db\r", "abc"},
+		{"abc\x01x\x05y\r", "xabcy"},
+		{"abcd\x7f\x7f\r", "ab"},
+		{"one two\x17three\r", "one three"},
+		{"one two\x1bbX\r", "one Xtwo"},
+		{"one two\x1b[1;5D\x0b\r", "one "},
+		{"abc\x1b[D\x1b[D\x1b[3~\r", "ac"},
+		{"abc\x1b[D\x15\r", "c"},
+	}
+	for _, tt := range tests {
+		if got := edit(&lineEditor{}, tt.keys); got != tt.want {
+			t.Errorf("Failed: keys %q edited to '%s', expected '%s'", tt.keys, got, tt.want)
+		}
+	}
+
+	ed := &lineEditor{history: []string{"first", "second"}}
+	if got := edit(ed, "x\x1b[A\x1b[A\r"); got != "first" {
+		t.Errorf("Failed: <Up> twice should recall 'first', got '%s'", got)
+	}
+	if got := edit(ed, "x\x1b[A\x1b[B\r"); got != "x" {
+		t.Errorf("Failed: <Up> <Down> should bring back the typed line, got '%s'", got)
+	}
+	if _, ok := ed.edit(strings.NewReader("\x04"), ioutil.Discard); ok {
+		t.Error("Failed: ^D on an empty line should end input.")
+	}
+
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpCompletion")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("save", "save", func() {})
+	tmpMenu.AddMenuEntry("sort", "sort", func() {})
+	tmpMenu.AddMenuEntry("load", "load", func() {})
+	tmpMenu.finalize()
+	ed = &lineEditor{complete: tmpMenu.completions}
+	if got := edit(ed, "l\t\r"); got != "load" {
+		t.Errorf("Failed: <Tab> should complete 'l' to 'load', got '%s'", got)
+	}
+	if got := edit(ed, "sa\t\r"); got != "save" {
+		t.Errorf("Failed: <Tab> should complete 'sa' to 'save', got '%s'", got)
+	}
+	if got := edit(ed, "s\t\r"); got != "s" {
+		t.Errorf("Failed: <Tab> with several completions should keep 's', got '%s'", got)
+	}
+	if got := tmpMenu.completions("!"); len(got) != 0 {
+		t.Errorf("Failed: history commands should not complete while the history is off, got %v", got)
+	}
+	MenuOptions.SetHistoryLimit(10)
+	defer MenuOptions.SetHistoryLimit(defhistoryLimit)
+	if got := tmpMenu.completions("!"); len(got) != 2 {
+		t.Errorf("Failed: history commands should complete, got %v", got)
+	}
+}
+
+func TestSingleKey(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	press := func(keys string) (string, bool) {
+		key, ok, _ := keyPress(strings.NewReader(keys), ioutil.Discard)
+		return key, ok
+	}
+	if key, ok := press("y"); key != "y" || !ok {
+		t.Errorf("Failed: pressing 'y' should read 'y', got '%s'", key)
+	}
+	if key, _ := press("\x1b[A\x1b[3~n"); key != "n" {
+		t.Errorf("Failed: arrow and function keys should be skipped, got '%s'", key)
+	}
+	if key, ok := press("\r"); key != "" || !ok {
+		t.Error("Failed: <RET> should read as blank input.")
+	}
+	if _, ok := press(""); ok {
+		t.Error("Failed: end of input should not be ok.")
+	}
+
+	tmpMenu := NewMenu("TmpSingleKey")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("y", "yes", func() {})
+	tmpMenu.AddMenuEntry("n", "no", func() {})
+	tmpMenu.SetSingleKey(true)
+	if report, _ := tmpMenu.doValidate(); strings.Contains(report, "longer than one") {
+		t.Errorf("Failed: one character keys should not be reported, got %s", report)
+	}
+	tmpMenu.AddMenuEntry("maybe", "maybe", func() {})
+	report, _ := tmpMenu.doValidate()
+	if !strings.Contains(report, "'maybe'") {
+		t.Errorf("Failed: a long key should be reported for a single key menu, got '%s'", report)
+	}
+	if !strings.Contains(report, "kill phrase") {
+		t.Errorf("Failed: a long kill phrase should be reported for a single key menu, got '%s'", report)
+	}
+	tmpMenu.finalize()
+	if tmpMenu.singleKeys() {
+		t.Error("Failed: a menu with a long key can't be read a key at a time.")
+	}
+
+	numbered := NewMenu("TmpSingleKeyAuto")
+	numbered.SetMenuBreakItem("q", "Quit", func() {})
+	numbered.SetAutoKeys(AutoKeyNumeric, true)
+	numbered.SetSingleKey(true)
+	for i := 0; i < 9; i++ {
+		numbered.AddMenuEntry(fmt.Sprintf("id%d", i), "entry", func() {})
+	}
+	if report, _ := numbered.doValidate(); strings.Contains(report, "longer than one") {
+		t.Errorf("Failed: 9 auto keys are one character each, got '%s'", report)
+	}
+	numbered.AddMenuEntry("id9", "entry", func() {})
+	if report, _ := numbered.doValidate(); !strings.Contains(report, "'10'") {
+		t.Errorf("Failed: auto key '10' should be reported for a single key menu, got '%s'", report)
+	}
+}
+
+func TestFullScreen(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	var out bytes.Buffer
+	screen = fullScreenState{active: true, out: &out, cols: 40, rows: 20}
+	defer func() { screen = fullScreenState{} }()
+
+	tmpMenu := NewMenu("TmpScreen")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "alpha", func() {})
+	tmpMenu.AddMenuEntry("b", "beta", func() {})
+	tmpMenu.AddMenuEntry("c", "gamma", func() {})
+	tmpMenu.finalize()
+	tmpMenu.displayMenu()
+	if drawn := out.String(); !strings.Contains(drawn, "TmpScreen") || !strings.Contains(drawn, ansiReverse+"a :  alpha") {
+		t.Errorf("Failed: full screen should show the title and highlight the first entry, got %q", drawn)
+	}
+	if screen.paneTop != 2+4+1+2 {
+		t.Errorf("Failed: the output pane should start below the prompt line, starts at %d", screen.paneTop)
+	}
+
+	tests := []struct{ keys, want string }{
+		{"\r", "a"},
+		{"\x1b[B\x1b[B\r", "c"},
+		{"\x1b[A\r", "b"},
+		{"\x1b[F\r", "q"},
+		{"\x1b[H\r", "a"},
+		{"\x1b[6~\r", "q"},
+		{"bx\x7f\r", "b"},
+		{"zz\x1bx\x1b[A\r", "c"},
+	}
+	for _, tt := range tests {
+		if got, _ := tmpMenu.navigate(strings.NewReader(tt.keys)); got != tt.want {
+			t.Errorf("Failed: keys %q chose '%s', expected '%s'", tt.keys, got, tt.want)
+		}
+	}
+	if _, ok := tmpMenu.navigate(strings.NewReader("")); ok {
+		t.Error("Failed: end of input should not be ok.")
+	}
+}
+
+type failingRenderer struct{}
+
+func (failingRenderer) Render(w io.Writer, view *MenuView) error {
+	fmt.Fprint(w, "half a menu")
+	return errors.New("out of ink")
+}
+
+func TestRenderers(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpRender")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "alpha", func() {})
+	tmpMenu.AddSection("s", "Extras")
+	tmpMenu.AddSectionEntry("s", "b", "beta", func() {})
+	tmpMenu.SetEntryEnabled("b", func() bool { return false }, "later")
+	tmpMenu.finalize()
+	tmpMenu.refreshEntryStates()
+
+	view := tmpMenu.view()
+	if len(view.Entries) != 2 || view.Quit.Key != "q" || !view.Entries[1].NewSection || !view.Entries[1].Disabled {
+		t.Errorf("Failed: unexpected view %+v", view)
+	}
+
+	render := func(r Renderer) string {
+		var b bytes.Buffer
+		tmpMenu.SetRenderer(r)
+		tmpMenu.render(&b)
+		return b.String()
+	}
+	classic := render(nil)
+	for _, want := range []string{"TmpRender\n", "a :  alpha", "--- Extras ---", "(unavailable: later)", "q :  Quit", MenuOptions.menuPrompt} {
+		if !strings.Contains(classic, want) {
+			t.Errorf("Failed: classic layout should contain '%s', got:\n%s", want, classic)
+		}
+	}
+	if boxed := render(BoxedRenderer{}); !strings.Contains(boxed, "│ TmpRender") || !strings.Contains(boxed, "└") {
+		t.Errorf("Failed: boxed layout should be drawn in a box, got:\n%s", boxed)
+	}
+	if compact := render(CompactRenderer{}); strings.Count(compact, "\n") != 1 || !strings.Contains(compact, "[a] alpha  [b] beta (unavailable)  [q] Quit") {
+		t.Errorf("Failed: compact layout should be one line, got %q", compact)
+	}
+
+	var decoded MenuView
+	if err := json.Unmarshal([]byte(render(JSONRenderer{})), &decoded); err != nil || decoded.Title != "TmpRender" || len(decoded.Entries) != 2 {
+		t.Errorf("Failed: JSON layout should decode to the view, got %+v, %v", decoded, err)
+	}
+
+	if got := render(failingRenderer{}); got != classic {
+		t.Errorf("Failed: a failing renderer should fall back to the classic layout, got:\n%s", got)
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	tmpMenu := NewMenu("TmpTemplate")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "alpha", func() {})
+	tmpMenu.AddMenuEntry("bb", "beta", func() {})
+	tmpMenu.finalize()
+	render := func(r Renderer) string {
+		var b bytes.Buffer
+		tmpMenu.SetRenderer(r)
+		tmpMenu.render(&b)
+		return b.String()
+	}
+
+	tr, err := NewTemplateRenderer(ClassicTemplate)
+	if err != nil {
+		t.Fatalf("Failed: ClassicTemplate should parse: %s", err)
+	}
+	if got := render(tr); !strings.Contains(got, "TmpTemplate\n") || !strings.Contains(got, "a  :  alpha\nbb :  beta\nq  :  Quit\n") {
+		t.Errorf("Failed: ClassicTemplate should lay out like the classic layout, got:\n%s", got)
+	}
+
+	MenuOptions.SetColorMode(ColorAlways)
+	defer MenuOptions.SetColorMode(ColorAuto)
+	tr, _ = NewTemplateRenderer(`{{upper .Title}}|{{range entries .}}{{padLeft 3 .Key}}={{.Hint}};{{end}}{{color "bold" .Prompt}}`)
+	if got := render(tr); got != "TMPTEMPLATE|  a=alpha; bb=beta;  q=Quit;\x1b[1m"+MenuOptions.menuPrompt+ansiReset {
+		t.Errorf("Failed: template functions gave %q", got)
+	}
+
+	if _, err := NewTemplateRenderer(`{{range}}`); err == nil {
+		t.Error("Failed: a template that does not parse should return an error.")
+	}
+
+	classic := render(nil)
+	tr, _ = NewTemplateRenderer(`{{color "mauve" .Title}}`)
+	if got := render(tr); got != classic {
+		t.Errorf("Failed: a failing template should fall back to the classic layout, got:\n%s", got)
+	}
+	if err := tr.Render(ioutil.Discard, tmpMenu.view()); err != nil {
+		t.Error("Failed: a failed template should not fail again, it uses the classic layout.")
+	}
+}
+
+func TestThemes(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	defer MenuOptions.SetTheme(ThemePlain)
+	defer MenuOptions.SetColorMode(ColorAuto)
+
+	theme, err := parseTheme("# mine\nbase = ocean\nkey = bold white on red\nhint = default # plain\n")
+	if err != nil {
+		t.Fatalf("Failed: theme should parse: %s", err)
+	}
+	if theme.Key != (Style{Fg: ColorWhite, Bg: ColorRed, Bold: true}) || theme.Hint != (Style{}) || theme.Section != ThemeOcean.Section {
+		t.Errorf("Failed: unexpected theme %+v", theme)
+	}
+	if Color(42).String() != "Color(42)" || ColorMode(-1).String() != "ColorMode(-1)" || ColorRed.String() != "red" {
+		t.Error("Failed: colours outside their range should print with their number.")
+	}
+	for _, bad := range []string{"key bold", "base = neon", "knob = red", "key = mauve", "key = red on"} {
+		if _, err := parseTheme(bad); err == nil {
+			t.Errorf("Failed: theme '%s' should not parse.", bad)
+		}
+	}
+
+	MenuOptions.SetTheme(ThemeDefault)
+	MenuOptions.SetColorMode(ColorAlways)
+	os.Setenv("NO_COLOR", "1")
+	if got := ThemeDefault.Key.Paint("k"); got != "\x1b[0;01;39;33;49mk"+ansiReset {
+		t.Errorf("Failed: ColorAlways should paint, got %q", got)
+	}
+	MenuOptions.SetColorMode(ColorAuto)
+	if got := ThemeDefault.Key.Paint("k"); got != "k" {
+		t.Errorf("Failed: NO_COLOR should turn colours off, got %q", got)
+	}
+	os.Unsetenv("NO_COLOR")
+
+	//columns stay aligned when styles of different lengths are painted
+	MenuOptions.SetColorMode(ColorAlways)
+	tmpMenu := NewMenu("TmpTheme")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "alpha", func() {})
+	tmpMenu.AddMenuEntry("b", "b", func() {})
+	tmpMenu.SetEntryEnabled("b", func() bool { return false }, "later")
+	tmpMenu.SetEntryBadge("a", func() string { return "1" })
+	tmpMenu.finalize()
+	tmpMenu.refreshEntryStates()
+	var painted, plain bytes.Buffer
+	tmpMenu.render(&painted)
+	MenuOptions.SetColorMode(ColorNever)
+	tmpMenu.render(&plain)
+	strip := regexp.MustCompile("\x1b\\[[0-9;]*m")
+	if got := strip.ReplaceAllString(painted.String(), ""); got == painted.String() || got != plain.String() {
+		t.Errorf("Failed: painted columns should align as plain ones, got:\n%s\nexpected:\n%s", got, plain.String())
+	}
+}
+
+func TestLayout(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	defer MenuOptions.SetLineWidth(0)
+	defer MenuOptions.SetLongHints(LongHintWrap)
+
+	for s, width := range map[string]int{"abc": 3, "日本": 4, "🍕!": 3, "café": 4, "\x1b[0;01;39;33;49mk\x1b[0m": 1, "": 0} {
+		if got := displayWidth(s); got != width {
+			t.Errorf("Failed: displayWidth(%q) should be %d, got %d", s, width, got)
+		}
+	}
+	if got := truncate("日本語です", 7); got != "日本語"+ellipsis || displayWidth(got) > 7 {
+		t.Errorf("Failed: unexpected truncation %q", got)
+	}
+	//a Latin-1 hint is not valid UTF-8, each byte takes a column
+	latin1 := strings.Repeat("\xe9", 20)
+	lines := fitHint(latin1, 12, LongHintWrap)
+	if len(lines) != 2 || lines[0] != latin1[:12] || lines[1] != hangIndent+latin1[12:] {
+		t.Errorf("Failed: an invalid UTF-8 hint should wrap at its bytes, got %q", lines)
+	}
+	if got := truncate(latin1, 12); got != latin1[:11]+ellipsis {
+		t.Errorf("Failed: an invalid UTF-8 hint should truncate at its bytes, got %q", got)
+	}
+
+	//without wide characters alignCells lines up as tabwriter does
+	rows := [][]string{{"a", ": ", "x", "[1]"}, {"bbb", ": ", "yy", ""}, {"header"}, {"cc", ": ", "z"}}
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 1, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	if got := strings.Join(alignCells(rows, 1, false), "\n") + "\n"; got != b.String() {
+		t.Errorf("Failed: alignCells should match tabwriter, got:\n%s\nexpected:\n%s", got, b.String())
+	}
+
+	tmpMenu := NewMenu("TmpLayout")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("日本", "kanji", func() {})
+	tmpMenu.AddMenuEntry("a", "one two three four five six seven eight nine ten", func() {})
+	tmpMenu.finalize()
+	tmpMenu.refreshEntryStates()
+	MenuOptions.SetLineWidth(30)
+	for mode, expected := range map[LongHintMode]string{
+		LongHintWrap:     "a    :  one two three four\n          five six seven eight\n          nine ten\n日本 :  kanji\n",
+		LongHintTruncate: "a    :  one two three four fi" + ellipsis + "\n日本 :  kanji\n",
+		LongHintNone:     "a    :  one two three four five six seven eight nine ten\n日本 :  kanji\n",
+	} {
+		MenuOptions.SetLongHints(mode)
+		var out bytes.Buffer
+		tmpMenu.render(&out)
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Failed: %s, unexpected layout:\n%s\nexpected:\n%s", mode, out.String(), expected)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+
+	tmpMenu := NewMenu("TmpColumns")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	for i := 1; i <= 5; i++ {
+		tmpMenu.AddMenuEntry(fmt.Sprintf("k%d", i), fmt.Sprintf("hint %d", i), func() {})
+	}
+	tmpMenu.AddMenuEntry("long", "a longer hint", func() {})
+	tmpMenu.SetEntryBadge("k2", func() string { return "4" })
+	if err := tmpMenu.SetColumns(-1); err == nil {
+		t.Errorf("Failed: negative columns should be refused.")
+	}
+	tmpMenu.finalize()
+	tmpMenu.refreshEntryStates()
+
+	render := func(view *MenuView) string {
+		var b bytes.Buffer
+		ClassicRenderer{}.Render(&b, view)
+		return b.String()
+	}
+	tmpMenu.SetColumns(2)
+	expected := "k1 :  hint 1       k4   :  hint 4\n" +
+		"k2 :  hint 2 [4]   k5   :  hint 5\n" +
+		"k3 :  hint 3       long :  a longer hint\n" +
+		"\nq  :  Quit\n"
+	if got := render(tmpMenu.view()); !strings.Contains(got, expected) {
+		t.Errorf("Failed: unexpected columns:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	//automatic columns only when the entries do not fit the height
+	tmpMenu.SetColumns(0)
+	view := tmpMenu.view()
+	view.Height = 20
+	if got := render(view); !strings.Contains(got, "k1   :  hint 1") {
+		t.Errorf("Failed: entries fitting the height should be in one column:\n%s", got)
+	}
+	view.Height, view.Width = 10, 40
+	if got := render(view); !strings.Contains(got, "k2 :  hint 2 [4]   k5   :  hint 5\n") {
+		t.Errorf("Failed: entries not fitting the height should be in columns:\n%s", got)
+	}
+	view.Width = 30
+	if got := render(view); !strings.Contains(got, "k2   :  hint 2        [4]\n") {
+		t.Errorf("Failed: columns should not be narrower than their hints:\n%s", got)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+
+	tmpMenu := NewMenu("TmpPaging")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	for i := 1; i <= 25; i++ {
+		tmpMenu.AddMenuEntry(fmt.Sprintf("r%02d", i), fmt.Sprintf("row %d", i), func() {})
+	}
+	if err := tmpMenu.SetPageSize(-1); err == nil {
+		t.Errorf("Failed: a negative page size should be refused.")
+	}
+	tmpMenu.SetPageSize(10)
+	tmpMenu.finalize()
+	tmpMenu.refreshEntryStates()
+
+	shown := func() string {
+		view := tmpMenu.view()
+		keys := []string{}
+		for _, ev := range view.Entries {
+			keys = append(keys, ev.Key)
+		}
+		return view.pageIndicator() + " " + strings.Join(keys, ",") + " " + view.Quit.Key
+	}
+	for _, step := range []struct{ input, expected string }{
+		{"", "[page 1/3] r01,r02,r03,r04,r05,r06,r07,r08,r09,r10 q"},
+		{"n", "[page 2/3] r11,r12,r13,r14,r15,r16,r17,r18,r19,r20 q"},
+		{"n", "[page 3/3] r21,r22,r23,r24,r25 q"},
+		{"n", "[page 3/3] r21,r22,r23,r24,r25 q"},
+		{"/ROW 2", "[page 1/1, 7 matching 'ROW 2'] r02,r20,r21,r22,r23,r24,r25 q"},
+		{"/5", "[page 1/1, 1 matching 'ROW 2' '5'] r25 q"},
+		{"/x", "[page 1/1, 0 matching 'ROW 2' '5' 'x']  q"},
+		{"/", "[page 1/3] r01,r02,r03,r04,r05,r06,r07,r08,r09,r10 q"},
+		{"p", "[page 1/3] r01,r02,r03,r04,r05,r06,r07,r08,r09,r10 q"},
+	} {
+		if step.input != "" && !tmpMenu.pagingCommand(step.input) {
+			t.Errorf("Failed: '%s' should be a paging command.", step.input)
+		}
+		if got := shown(); got != step.expected {
+			t.Errorf("Failed: after '%s' expected %q, got %q", step.input, step.expected, got)
+		}
+	}
+	if tmpMenu.pagingCommand("r01") {
+		t.Errorf("Failed: a Key should not be a paging command.")
+	}
+
+	//later filter texts only go through the entries matching the earlier ones
+	tmpMenu.pagingCommand("/row 1")
+	tmpMenu.pagingCommand("/0")
+	tmpMenu.view()
+	if len(tmpMenu.filtered) != 2 || len(tmpMenu.filtered[0]) != 11 || len(tmpMenu.filtered[1]) != 2 {
+		t.Errorf("Failed: unexpected filter matches %v", tmpMenu.filtered)
+	}
+
+	tmpMenu.AddMenuEntry("n", "next", func() {})
+	tmpMenu.reSet()
+	if report, _ := tmpMenu.doValidate(); !strings.Contains(report, "taken by a paging command") {
+		t.Errorf("Failed: Key 'n' should conflict with a paging command, got:\n%s", report)
+	}
+	if tmpMenu.filtered != nil {
+		t.Errorf("Failed: a changed Menu should filter again.")
+	}
+
+	//Start finalizes the Menu, which reports the conflicts
+	conflicts := NewMenu("TmpPagingKeys")
+	conflicts.SetMenuBreakItem("q", "Quit", func() {})
+	conflicts.AddMenuEntry("p", "print", func() {})
+	conflicts.AddMenuEntry("/x", "slash", func() {})
+	conflicts.AddMenuEntry("x", "x", func() {})
+	conflicts.SetPageSize(5)
+	MenuOptions.SetRunTimeErrMsgsDisplay(true)
+	MenuOptions.SetRunTimeErrMsgsPause(false)
+	defer MenuOptions.SetRunTimeErrMsgsPause(defrunTimeErrMsgsPause)
+	setInput("q\n")
+	output := captureOutput(func() { conflicts.Start() })
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	if !strings.Contains(output, "Key '/x' which is taken") || !strings.Contains(output, "Key 'p' which is taken") ||
+		strings.Contains(output, "Key 'x' which") {
+		t.Errorf("Failed: Start should report Keys '/x' and 'p', got:\n%s", output)
+	}
+
+	//auto keys skip the paging commands
+	lettered := NewMenu("TmpPagingAuto")
+	lettered.SetMenuBreakItem("q", "Quit", func() {})
+	lettered.SetAutoKeys(AutoKeyAlpha, true)
+	lettered.SetPageSize(5)
+	for i := 1; i <= 16; i++ {
+		lettered.AddMenuEntry(fmt.Sprintf("id%02d", i), "entry", func() {})
+	}
+	lettered.finalize()
+	if key := lettered.entries["id14"].value; key != "o" || lettered.entries["id16"].value != "s" {
+		t.Errorf("Failed: auto keys should skip 'n', 'p' and 'q', the 14th is '%s'", key)
+	}
+}
+
+func TestPager(t *testing.T) {
+	MenuOptions.SetRunTimeErrMsgsDisplay(false)
+	defer MenuOptions.SetOutputMode(OutputDirect)
+
+	stdout := os.Stdout
+	captured := false
+	if got := captureOutput(func() { captured = waiting.captured; fmt.Print("kept\n") }); got != "kept\n" || os.Stdout != stdout {
+		t.Errorf("Failed: unexpected captured output %q", got)
+	}
+	if !captured || waiting.captured {
+		t.Error("Failed: resize redraws should only be held back while output is captured.")
+	}
+	if got := OutputMode(9).String(); got != "OutputMode(9)" {
+		t.Errorf("Failed: an invalid OutputMode should print as 'OutputMode(9)', got '%s'", got)
+	}
+
+	tmpMenu := NewMenu("TmpPager")
+	tmpMenu.SetMenuBreakItem("q", "Quit", func() {})
+	tmpMenu.AddMenuEntry("a", "alpha", func() {})
+	tmpMenu.AddMenuEntry("b", "beta", func() {})
+	if err := tmpMenu.SetEntryOutput("q", OutputPager); err == nil {
+		t.Errorf("Failed: the break item should not be paged.")
+	}
+	tmpMenu.SetEntryOutput("b", OutputDirect)
+	MenuOptions.SetOutputMode(OutputPagerEnv)
+	if a, b := tmpMenu.entries["a"].outputMode(), tmpMenu.entries["b"].outputMode(); a != OutputPagerEnv || b != OutputDirect {
+		t.Errorf("Failed: unexpected output modes %s, %s", a, b)
+	}
+	tmpMenu.SetEntryOutput("a", OutputPager)
+	tmpMenu.entries["a"].fixedKey = true
+	if err := tmpMenu.ChangeMenuEntry("", "a", "c"); err != nil {
+		t.Errorf("Failed: unexpected error renaming an entry: %v", err)
+	} else if c := tmpMenu.entries["c"]; c.output != OutputPager || !c.fixedKey {
+		t.Errorf("Failed: a renamed entry should keep its output mode and fixed Key, got %s, %v", c.output, c.fixedKey)
+	}
+
+	if got := expandTabs("ab\tc\t日本\td"); got != "ab      c       日本    d" {
+		t.Errorf("Failed: unexpected tab expansion %q", got)
+	}
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[2] = strings.Repeat("w", 25)
+	p := &pager{title: "t", lines: lines, out: ioutil.Discard}
+	p.layout(20, 5)
+	if len(p.shown) != 31 || p.shown[3] != "wwwww" {
+		t.Errorf("Failed: long lines should wrap, got %q", p.shown[:5])
+	}
+	//output that is not valid UTF-8 wraps at its bytes, each byte takes a column
+	binary := &pager{title: "t", lines: []string{strings.Repeat("\xe9", 100), "\xff\tx"}, out: ioutil.Discard}
+	binary.layout(80, 5)
+	if len(binary.shown) != 3 || binary.shown[0] != strings.Repeat("\xe9", 80) || binary.shown[2] != "\xff       x" {
+		t.Errorf("Failed: invalid UTF-8 output should wrap at its bytes, got %q", binary.shown)
+	}
+	for _, step := range []struct {
+		keys string
+		top  int
+	}{
+		{" ", 5}, {"k", 4}, {"G", 26}, {"g", 0}, {"/LINE 2\r", 1}, {"n", 20}, {"N", 1},
+		{"?line 30\r", 1}, {"\x1b[6~", 6}, {"/\r", 26}, {"/zz\x7f\x7f\x7f", 26},
+	} {
+		in := strings.NewReader(step.keys)
+		for {
+			r, _, err := in.ReadRune()
+			if err != nil {
+				break
+			}
+			if p.key(in, r) {
+				t.Errorf("Failed: '%q' should not quit", step.keys)
+			}
+		}
+		if p.top != step.top {
+			t.Errorf("Failed: after %q the top line should be %d, got %d", step.keys, step.top, p.top)
+		}
+	}
+	if !p.key(strings.NewReader(""), 'q') {
+		t.Errorf("Failed: 'q' should quit")
+	}
+}
@@ -0,0 +1,288 @@
+package juusmenu
+
+//Colour themes: a Theme styles the parts of the menus, the breadcrumbs, Keys,
+//hints, section headers, the footer, the prompt, the func() brackets and the
+//warnings. Set one with MenuOptions.SetTheme() or MenuOptions.LoadTheme().
+//Colours are only used when the output is a terminal and the NO_COLOR
+//environment variable is not set, see MenuOptions.SetColorMode().
+//
+//A theme file has one element per line, '#' starts a comment:
+//
+//  base = ocean               start from a built in theme: plain default ocean contrast
+//  breadcrumbs = bold cyan    a colour, "on" a background colour, bold, dim
+//  warning = bold white on red
+//
+//The elements are breadcrumbs, key, hint, disabled, section, footer, prompt,
+//brackets and warning. The colours are black red green yellow blue magenta
+//cyan white and default.
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+//Color : an ANSI terminal colour, ColorDefault leaves the terminal's colour
+type Color int
+
+const (
+	//ColorDefault : the terminal's own colour
+	ColorDefault Color = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+var colorNames = [...]string{"default", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+func (c Color) String() string {
+	return enumString("Color", int(c), colorNames[:]...)
+}
+
+//Style : how a part of the menus looks
+type Style struct {
+	Fg, Bg    Color
+	Bold, Dim bool
+}
+
+//Theme : the Styles of the parts of the menus, see the top of this file
+type Theme struct {
+	Name                                       string
+	Breadcrumbs, Key, Hint, Disabled           Style
+	Section, Footer, Prompt, Brackets, Warning Style
+}
+
+//the built in Themes
+var (
+	//ThemePlain : no colours, the default
+	ThemePlain = Theme{Name: "plain"}
+	//ThemeDefault : a little colour on a dark or light terminal
+	ThemeDefault = Theme{
+		Name:        "default",
+		Breadcrumbs: Style{Bold: true},
+		Key:         Style{Fg: ColorYellow, Bold: true},
+		Disabled:    Style{Dim: true},
+		Section:     Style{Fg: ColorCyan},
+		Footer:      Style{Dim: true},
+		Brackets:    Style{Dim: true},
+		Warning:     Style{Fg: ColorRed, Bold: true},
+	}
+	//ThemeOcean : blues and greens
+	ThemeOcean = Theme{
+		Name:        "ocean",
+		Breadcrumbs: Style{Fg: ColorCyan, Bold: true},
+		Key:         Style{Fg: ColorGreen, Bold: true},
+		Hint:        Style{Fg: ColorCyan},
+		Disabled:    Style{Fg: ColorBlue, Dim: true},
+		Section:     Style{Fg: ColorBlue, Bold: true},
+		Footer:      Style{Fg: ColorBlue},
+		Prompt:      Style{Fg: ColorGreen},
+		Brackets:    Style{Fg: ColorBlue},
+		Warning:     Style{Fg: ColorYellow, Bold: true},
+	}
+	//ThemeContrast : strong colours, easy to tell apart
+	ThemeContrast = Theme{
+		Name:        "contrast",
+		Breadcrumbs: Style{Fg: ColorBlack, Bg: ColorWhite, Bold: true},
+		Key:         Style{Fg: ColorYellow, Bold: true},
+		Hint:        Style{Fg: ColorWhite},
+		Disabled:    Style{Dim: true},
+		Section:     Style{Fg: ColorMagenta, Bold: true},
+		Footer:      Style{Fg: ColorCyan},
+		Prompt:      Style{Fg: ColorYellow, Bold: true},
+		Brackets:    Style{Fg: ColorMagenta},
+		Warning:     Style{Fg: ColorWhite, Bg: ColorRed, Bold: true},
+	}
+)
+
+//builtinThemes : the built in Themes by name, for theme files
+var builtinThemes = map[string]*Theme{
+	"plain": &ThemePlain, "default": &ThemeDefault, "ocean": &ThemeOcean, "contrast": &ThemeContrast,
+}
+
+//ColorMode : when Themes are shown in colour, see MenuOptions.SetColorMode()
+type ColorMode int
+
+const (
+	//ColorAuto : Default. Colours when the output is a terminal and NO_COLOR is not set
+	ColorAuto ColorMode = iota
+	//ColorAlways : always colours
+	ColorAlways
+	//ColorNever : never colours
+	ColorNever
+)
+
+func (cm ColorMode) String() string {
+	return enumString("ColorMode", int(cm), "ColorAuto", "ColorAlways", "ColorNever")
+}
+
+//SetTheme : Sets the Theme of all menus, ThemePlain for none.
+func (mo *menuOptions) SetTheme(theme Theme) {
+	mo.theme = theme
+}
+
+//SetColorMode : Sets when the Theme is shown in colour, ColorAuto by default.
+func (mo *menuOptions) SetColorMode(mode ColorMode) {
+	mo.colorMode = mode
+}
+
+//LoadTheme : Reads a theme file, see the top of juusmenu_theme.go, and sets its Theme.
+//The Theme is named after the file. On an error the Theme is not changed.
+func (mo *menuOptions) LoadTheme(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		var theme Theme
+		if theme, err = parseTheme(string(data)); err == nil {
+			theme.Name = path
+			mo.theme = theme
+			return nil
+		}
+	}
+	errmsg := warn + fmt.Sprintf("LoadTheme method: theme file '%s' not loaded: %s", path, err)
+	alertUser(&errmsg)
+	return errors.New(errmsg)
+}
+
+//parseTheme : internal use, reads the lines of a theme file
+func parseTheme(text string) (Theme, error) {
+	theme := ThemePlain
+	for n, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return theme, fmt.Errorf("line %d: expected 'element = style'", n+1)
+		}
+		element, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.ToLower(strings.TrimSpace(parts[1]))
+		if element == "base" {
+			base, ok := builtinThemes[value]
+			if !ok {
+				return theme, fmt.Errorf("line %d: unknown theme '%s'", n+1, value)
+			}
+			theme = *base
+			continue
+		}
+		style := theme.element(element)
+		if style == nil {
+			return theme, fmt.Errorf("line %d: unknown element '%s'", n+1, element)
+		}
+		parsed, err := parseStyle(value)
+		if err != nil {
+			return theme, fmt.Errorf("line %d: %s", n+1, err)
+		}
+		*style = parsed
+	}
+	return theme, nil
+}
+
+//parseStyle : internal use, reads a style like "bold white on red"
+func parseStyle(text string) (Style, error) {
+	var style Style
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i++ {
+		switch word := words[i]; word {
+		case "bold":
+			style.Bold = true
+		case "dim":
+			style.Dim = true
+		case "on":
+			if i+1 == len(words) {
+				return style, errors.New("'on' without a colour")
+			}
+			i++
+			c, err := parseColor(words[i])
+			if err != nil {
+				return style, err
+			}
+			style.Bg = c
+		default:
+			c, err := parseColor(word)
+			if err != nil {
+				return style, err
+			}
+			style.Fg = c
+		}
+	}
+	return style, nil
+}
+
+//parseColor : internal use, a Color by name
+func parseColor(name string) (Color, error) {
+	for i, n := range colorNames {
+		if n == name {
+			return Color(i), nil
+		}
+	}
+	return ColorDefault, fmt.Errorf("unknown colour '%s'", name)
+}
+
+//element : internal use, the Style of a theme file element, nil if there is none
+func (theme *Theme) element(name string) *Style {
+	return map[string]*Style{
+		"breadcrumbs": &theme.Breadcrumbs, "key": &theme.Key, "hint": &theme.Hint,
+		"disabled": &theme.Disabled, "section": &theme.Section, "footer": &theme.Footer,
+		"prompt": &theme.Prompt, "brackets": &theme.Brackets, "warning": &theme.Warning,
+	}[name]
+}
+
+//colorsOn : internal use, true if the Theme is to be shown
+func colorsOn() bool {
+	return !MenuOptions.theme.isPlain() && colorAllowed()
+}
+
+//colorAllowed : internal use, true if colours may be used, see SetColorMode()
+func colorAllowed() bool {
+	switch MenuOptions.colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+}
+
+//isPlain : internal use, true if the Theme has no Styles
+func (theme Theme) isPlain() bool {
+	theme.Name = ""
+	return theme == Theme{}
+}
+
+//Paint : Returns text in the style, if colours are on, for Renderers. Every style
+//starts with a sequence of the same length, even an empty one, so a text/tabwriter
+//in your own Renderer, which counts them, still aligns columns of styled text.
+func (style Style) Paint(text string) string {
+	if !colorsOn() {
+		return text
+	}
+	return style.sequence() + text + ansiReset
+}
+
+//sequence : internal use, the ANSI sequence of the style, always 16 bytes. Attributes
+//not used are set to the default colour, which does nothing after the reset.
+func (style Style) sequence() string {
+	bold, dim, fg, bg := 39, 39, 39, 49
+	if style.Bold {
+		bold = 1
+	}
+	if style.Dim {
+		dim = 2
+	}
+	if style.Fg != ColorDefault {
+		fg = 29 + int(style.Fg)
+	}
+	if style.Bg != ColorDefault {
+		bg = 39 + int(style.Bg)
+	}
+	return fmt.Sprintf("\x1b[0;%02d;%02d;%02d;%02dm", bold, dim, fg, bg)
+}
@@ -0,0 +1,375 @@
+package juusmenu
+
+//Wizards: a sequence of steps (menus, choose-one menus, prompts and forms) whose
+//answers can decide which step comes next. The user can go back a step, with
+//the previous answers kept, and confirms a summary before the wizard completes.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//WizardEnd : return this from a SetStepNext func() to go straight to the summary
+const WizardEnd = ">>end<<"
+
+const (
+	wizardBackKey      = "<"
+	wizardBackHint     = "Back to the previous step"
+	wizardProgress     = "%s %s Step %d"
+	wizardProgressOf   = "%s %s Step %d/%d"
+	wizardFinishKey    = "y"
+	wizardCancelKey    = "c"
+	wizardSummaryTitle = "Summary"
+	wizardSummaryLine  = "  %s: %s"
+	wizardSummaryAsk   = "'%s' finish, '%s' back, '%s' cancel"
+	wizardNoAnswer     = "<none>"
+)
+
+//wizardStepKind : what a wizard step runs
+type wizardStepKind int
+
+const (
+	stepMenu wizardStepKind = iota
+	stepChoice
+	stepPrompt
+	stepForm
+)
+
+//wizardStep : one step of a Wizard
+type wizardStep struct {
+	name     string
+	kind     wizardStepKind
+	menu     *Menu
+	form     *Form
+	prompt   string
+	validate func(string) error
+	next     func(WizardAnswers) string
+}
+
+//WizardAnswers : the answers of a Wizard by step name. Menu and choose-one steps answer
+//the Key (ID for auto keyed menus) chosen, prompt steps the text typed and form
+//steps "submitted", with each field's value under "<step name>.<field name>".
+type WizardAnswers map[string]string
+
+//Wizard : a sequence of steps run one after the other, see NewWizard()
+type Wizard struct {
+	title      string
+	steps      []*wizardStep
+	answers    WizardAnswers
+	history    []int
+	onComplete func(WizardAnswers)
+	goBack     bool
+}
+
+//NewWizard : Returns a new Wizard. Add steps in the order they are normally run.
+//onComplete, which may be nil, runs with the answers of the steps the user went
+//through once the summary is confirmed.
+func NewWizard(title string, onComplete func(WizardAnswers)) *Wizard {
+	valueClean(&title, &unNamedMenuTitle, vIgnore, func() {})
+	return &Wizard{
+		title:      title,
+		answers:    make(WizardAnswers),
+		onComplete: onComplete,
+	}
+}
+
+//AddMenuStep : Adds a step running menu as a normal Menu, the step is done when the user
+//leaves it with its Break Item. The answer is the last entry the user ran. While
+//running, the wizard adds a '<' entry to go back a step.
+func (wiz *Wizard) AddMenuStep(name string, menu *Menu) error {
+	if menu == nil {
+		return wiz.stepError("AddMenuStep", name, "menu was nil")
+	}
+	return wiz.addStep(&wizardStep{name: name, kind: stepMenu, menu: menu})
+}
+
+//AddChoiceStep : Adds a step running menu as a ChooseOne Menu, the answer is the entry
+//chosen. The menu's Break Item cancels the wizard. While running, the wizard adds
+//a '<' entry to go back a step.
+func (wiz *Wizard) AddChoiceStep(name string, menu *Menu) error {
+	if menu == nil {
+		return wiz.stepError("AddChoiceStep", name, "menu was nil")
+	}
+	return wiz.addStep(&wizardStep{name: name, kind: stepChoice, menu: menu})
+}
+
+//AddPromptStep : Adds a step asking for a line of input. validate, which may be nil,
+//rejects answers by returning an error. Typing '<' goes back a step, <RET> keeps the
+//previous answer, or cancels the wizard if there is none.
+func (wiz *Wizard) AddPromptStep(name, prompt string, validate func(string) error) error {
+	return wiz.addStep(&wizardStep{name: name, kind: stepPrompt, prompt: prompt, validate: validate})
+}
+
+//AddFormStep : Adds a step running form, the step is done when the form is submitted.
+//Canceling the form cancels the wizard. While running, the wizard adds a '<' entry
+//to go back a step. The form keeps its values, so going back shows them again.
+func (wiz *Wizard) AddFormStep(name string, form *Form) error {
+	if form == nil {
+		return wiz.stepError("AddFormStep", name, "form was nil")
+	}
+	return wiz.addStep(&wizardStep{name: name, kind: stepForm, form: form, menu: form.menu})
+}
+
+//SetStepNext : next is called with the answers so far when the step is done and returns
+//the name of the step to run next, "" for the following step or WizardEnd for the summary.
+func (wiz *Wizard) SetStepNext(name string, next func(WizardAnswers) string) error {
+	idx := wiz.stepIndex(name)
+	if idx < 0 {
+		return wiz.stepError("SetStepNext", name, "step does not exist")
+	}
+	wiz.steps[idx].next = next
+	return nil
+}
+
+//Answers : returns the answers given so far, including those of steps the user went
+//back from
+func (wiz *Wizard) Answers() WizardAnswers {
+	return wiz.answers
+}
+
+//Start : Runs the wizard from its first step. completed is true if the user confirmed
+//the summary, after onComplete has run. Previous answers are kept between runs.
+func (wiz *Wizard) Start() (completed bool, err error) {
+	if len(wiz.steps) == 0 {
+		errmsg := warn + fmt.Sprintf("Wizard '%s' has no steps, not Start()'ing.", wiz.title)
+		alertUser(&errmsg)
+		return false, errors.New(errmsg)
+	}
+
+	wiz.history = wiz.history[:0]
+	current := 0
+	for !killSwitch {
+		if current == len(wiz.steps) {
+			switch wiz.confirmSummary() {
+			case wizardFinishKey:
+				if wiz.onComplete != nil {
+					wiz.onComplete(wiz.pathAnswers())
+				}
+				return true, nil
+			case wizardBackKey:
+				current = wiz.back(current)
+				continue
+			}
+			return false, nil
+		}
+
+		wiz.goBack = false
+		done, err := wiz.runStep(current)
+		switch {
+		case err != nil:
+			return false, err
+		case wiz.goBack:
+			current = wiz.back(current)
+			continue
+		case !done:
+			return false, nil
+		}
+
+		wiz.history = append(wiz.history, current)
+		if current, err = wiz.nextStep(current); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+//runStep : internal use, runs a step and stores its answer. done is false if the user
+//canceled the wizard, wiz.goBack is set if the user asked to go back.
+func (wiz *Wizard) runStep(idx int) (done bool, err error) {
+	step := wiz.steps[idx]
+	progress := fmt.Sprintf(wizardProgress, wiz.title, MenuOptions.menuSeparator, len(wiz.history)+1)
+	if left := wiz.stepsLeft(idx); left > 0 {
+		progress = fmt.Sprintf(wizardProgressOf, wiz.title, MenuOptions.menuSeparator, len(wiz.history)+1, len(wiz.history)+left)
+	}
+
+	if step.kind == stepPrompt {
+		fmt.Println("\n" + progress)
+		return wiz.runPrompt(step), nil
+	}
+
+	menu := step.menu
+	menu.crumbPrefix = progress
+	defer func() { menu.crumbPrefix = "" }()
+	if len(wiz.history) > 0 {
+		if _, exists := menu.entries[wizardBackKey]; !exists {
+			menu.addStateEntry(wizardBackKey, wizardBackHint, func() {
+				wiz.goBack = true
+				menu.Close()
+			}, nil)
+			menu.entries[wizardBackKey].fixedKey = true
+			defer menu.RemoveMenuEntry(wizardBackKey)
+		}
+	}
+
+	switch step.kind {
+	case stepForm:
+		submitted, err := step.form.Start()
+		if err != nil || !submitted {
+			return false, err
+		}
+		wiz.answers[step.name] = "submitted"
+		for name, value := range step.form.Values() {
+			wiz.answers[step.name+"."+name] = value
+		}
+		return true, nil
+	case stepChoice:
+		wasChooseOne := menu.isChooseOne
+		menu.SetChooseOne(true)
+		defer menu.SetChooseOne(wasChooseOne)
+	}
+
+	if err = menu.Start(); err != nil {
+		return false, err
+	}
+	if killSwitch || wiz.goBack {
+		return false, nil
+	}
+	if step.kind == stepChoice && menu.LastChoice() == "" {
+		return false, nil
+	}
+	wiz.answers[step.name] = menu.LastChoice()
+	return true, nil
+}
+
+//runPrompt : internal use, asks for a prompt step's answer until it validates
+func (wiz *Wizard) runPrompt(step *wizardStep) (done bool) {
+	previous, hasPrevious := wiz.answers[step.name]
+	prompt := step.prompt
+	if len(wiz.history) > 0 {
+		prompt = prompt + fmt.Sprintf(" ('%s' goes back)", wizardBackKey)
+	}
+	if hasPrevious {
+		prompt = prompt + fmt.Sprintf("\n<RET> keeps '%s'", previous)
+	}
+	for {
+		input := GetUserInput(prompt)
+		switch {
+		case input == wizardBackKey && len(wiz.history) > 0:
+			wiz.goBack = true
+			return false
+		case input == "" && hasPrevious:
+			return true
+		case input == "":
+			return false
+		}
+		if step.validate != nil {
+			if err := step.validate(input); err != nil {
+				fmt.Printf("'%s' not accepted: %s\n", input, err)
+				continue
+			}
+		}
+		wiz.answers[step.name] = input
+		return true
+	}
+}
+
+//confirmSummary : internal use, shows the answers and asks to finish, go back or cancel
+func (wiz *Wizard) confirmSummary() string {
+	fmt.Println("\n" + wiz.title + fmt.Sprintf(" %s ", MenuOptions.menuSeparator) + wizardSummaryTitle)
+	fmt.Println("------------------------------")
+	for _, idx := range wiz.history {
+		name := wiz.steps[idx].name
+		answer := wiz.answers[name]
+		if answer == "" {
+			answer = wizardNoAnswer
+		}
+		fmt.Println(fmt.Sprintf(wizardSummaryLine, name, answer))
+		if wiz.steps[idx].kind == stepForm {
+			for _, f := range wiz.steps[idx].form.fields {
+				fmt.Println(fmt.Sprintf("  "+wizardSummaryLine, f.label, wiz.answers[name+"."+f.name]))
+			}
+		}
+	}
+	for {
+		switch input := GetUserInput(fmt.Sprintf(wizardSummaryAsk, wizardFinishKey, wizardBackKey, wizardCancelKey)); input {
+		case wizardFinishKey, wizardBackKey:
+			return input
+		case wizardCancelKey, "":
+			return wizardCancelKey
+		}
+	}
+}
+
+//back : internal use, returns the step to go back to from current
+func (wiz *Wizard) back(current int) int {
+	if len(wiz.history) == 0 {
+		return current
+	}
+	prev := wiz.history[len(wiz.history)-1]
+	wiz.history = wiz.history[:len(wiz.history)-1]
+	return prev
+}
+
+//nextStep : internal use, returns the step following idx, or len(wiz.steps) for the summary
+func (wiz *Wizard) nextStep(idx int) (int, error) {
+	step := wiz.steps[idx]
+	if step.next == nil {
+		return idx + 1, nil
+	}
+	switch name := step.next(wiz.answers); name {
+	case "":
+		return idx + 1, nil
+	case WizardEnd:
+		return len(wiz.steps), nil
+	default:
+		if next := wiz.stepIndex(name); next >= 0 {
+			return next, nil
+		}
+		errmsg := warn + fmt.Sprintf("Wizard '%s': step '%s' asked for next step '%s' which does not exist.", wiz.title, step.name, name)
+		alertUser(&errmsg)
+		return 0, errors.New(errmsg)
+	}
+}
+
+//stepsLeft : internal use, the number of steps from idx to the summary, 0 if a
+//SetStepNext func() decides the way there, as the answers are not known yet
+func (wiz *Wizard) stepsLeft(idx int) int {
+	for _, step := range wiz.steps[idx:] {
+		if step.next != nil {
+			return 0
+		}
+	}
+	return len(wiz.steps) - idx
+}
+
+//pathAnswers : internal use, the answers of the steps the user went through
+func (wiz *Wizard) pathAnswers() WizardAnswers {
+	result := make(WizardAnswers)
+	for _, idx := range wiz.history {
+		name := wiz.steps[idx].name
+		for k, v := range wiz.answers {
+			if k == name || strings.HasPrefix(k, name+".") {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
+//addStep : internal use, validates and appends a step
+func (wiz *Wizard) addStep(step *wizardStep) error {
+	valueClean(&step.name, &emptyString, vIgnore, func() {})
+	if step.name == "" || wiz.stepIndex(step.name) >= 0 || step.name == WizardEnd {
+		return wiz.stepError("Add step", step.name, "step name is empty or already used")
+	}
+	wiz.steps = append(wiz.steps, step)
+	return nil
+}
+
+//stepIndex : internal use, returns the position of the named step or -1
+func (wiz *Wizard) stepIndex(name string) int {
+	for i, s := range wiz.steps {
+		if s.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+//stepError : internal use, reports a problem with a step
+func (wiz *Wizard) stepError(methodName, name, problem string) error {
+	errmsg := warn + fmt.Sprintf("%s method: Wizard '%s', step '%s': %s.", methodName, wiz.title, name, problem)
+	alertUser(&errmsg)
+	return errors.New(errmsg)
+}